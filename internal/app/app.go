@@ -12,6 +12,7 @@ import (
 	auth_interceptors "github.com/rkchv/auth/pkg/user_v1/auth/grpc-interceptors"
 	"github.com/rkchv/chat/lib/closer"
 	"github.com/rkchv/chat/lib/logger"
+	"github.com/rkchv/chat/lib/rate_limiter"
 	"github.com/rkchv/chat/lib/tracer"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
@@ -31,8 +32,15 @@ import (
 	"github.com/rkchv/chat/pkg/chat_v1"
 )
 
+const (
+	consumerRestartDelay = 5 * time.Second
+	// staleUploadsInterval как часто удаляются неприкрепленные файлы
+	staleUploadsInterval = time.Hour
+)
+
 type App struct {
 	grpc             *grpc.Server
+	chatServer       *grpc_server.Server
 	srvProvider      *serviceProvider
 	traceExporter    *otlptrace.Exporter
 	prometheusServer *http.Server
@@ -46,28 +54,67 @@ func NewApp(ctx context.Context) *App {
 }
 
 func (a *App) init(ctx context.Context) {
-	lg := logger.SetupLogger(logger.Env(a.srvProvider.Config().Env))
+	lg := a.srvProvider.Logger()
+	rateLimit := interceptors.NewRateLimit(a.srvProvider.UserRateLimiter(), a.srvProvider.ChatRateLimiter())
 	a.grpc = grpc.NewServer(
 		grpc.Creds(insecure.NewCredentials()),
 		grpc.StatsHandler(otelgrpc.NewServerHandler()),
-		grpc.StreamInterceptor(interceptors.NewStreamAccessInterceptor([]string{
-			chat_v1.ChatV1_Connect_FullMethodName,
-		}, a.srvProvider.Config().SecretKey)),
+		grpc.ChainStreamInterceptor(
+			interceptors.NewStreamLoggerInterceptor(lg),
+			interceptors.NewStreamAccessInterceptor([]string{
+				chat_v1.ChatV1_Connect_FullMethodName,
+				chat_v1.ChatV1_Subscribe_FullMethodName,
+				chat_v1.ChatV1_Session_FullMethodName,
+				chat_v1.ChatV1_UploadAttachment_FullMethodName,
+				chat_v1.ChatV1_DownloadAttachment_FullMethodName,
+			}, a.srvProvider.Config().SecretKey),
+			rateLimit.Stream(),
+		),
 		grpc.ChainUnaryInterceptor(
 			interceptors.NewLoggerInterceptor(lg),
 			auth_interceptors.NewAccessInterceptor([]string{
 				chat_v1.ChatV1_Create_FullMethodName,
 				chat_v1.ChatV1_SendMessage_FullMethodName,
 				chat_v1.ChatV1_Delete_FullMethodName,
+				chat_v1.ChatV1_ListMessages_FullMethodName,
+				chat_v1.ChatV1_ListChats_FullMethodName,
+				chat_v1.ChatV1_SearchMessages_FullMethodName,
+				chat_v1.ChatV1_ListMentions_FullMethodName,
+				chat_v1.ChatV1_EditMessage_FullMethodName,
+				chat_v1.ChatV1_DeleteMessage_FullMethodName,
+				chat_v1.ChatV1_MarkRead_FullMethodName,
+				chat_v1.ChatV1_SeenBy_FullMethodName,
+				chat_v1.ChatV1_AddMembers_FullMethodName,
+				chat_v1.ChatV1_RemoveMember_FullMethodName,
+				chat_v1.ChatV1_LeaveChat_FullMethodName,
+				chat_v1.ChatV1_ListMembers_FullMethodName,
+				chat_v1.ChatV1_SetMemberRole_FullMethodName,
+				chat_v1.ChatV1_TransferOwnership_FullMethodName,
+				chat_v1.ChatV1_PinMessage_FullMethodName,
+				chat_v1.ChatV1_CreateInvite_FullMethodName,
+				chat_v1.ChatV1_RevokeInvite_FullMethodName,
+				chat_v1.ChatV1_JoinByInvite_FullMethodName,
+				chat_v1.ChatV1_BanMember_FullMethodName,
+				chat_v1.ChatV1_UnbanMember_FullMethodName,
+				chat_v1.ChatV1_MuteMember_FullMethodName,
+				chat_v1.ChatV1_UnmuteMember_FullMethodName,
+				chat_v1.ChatV1_GetPresence_FullMethodName,
 			}, a.srvProvider.Config().SecretKey),
+			rateLimit.Unary(),
 		),
 	)
 
-	reflection.Register(a.grpc)
-	chat_v1.RegisterChatV1Server(a.grpc, grpc_server.NewServer(
+	a.chatServer = grpc_server.NewServer(
 		a.srvProvider.ChatService(ctx),
-		a.srvProvider.Config().ChatGarbageCycle,
-		a.srvProvider.Config().ChatExpired))
+		a.srvProvider.Broker(ctx),
+		a.srvProvider.Config().ChatExpired,
+		a.srvProvider.Config().ChatHistoryLimit,
+		a.srvProvider.Config().Session,
+		a.srvProvider.SubscriberQueue(),
+		a.srvProvider.Logger())
+
+	reflection.Register(a.grpc)
+	chat_v1.RegisterChatV1Server(a.grpc, a.chatServer)
 }
 
 func (a *App) initTracing(ctx context.Context, serviceName string) {
@@ -114,6 +161,12 @@ func (a *App) Start() error {
 
 	log.Printf("ChatAPI service started on %s\n", a.srvProvider.Config().GRPC.Address())
 
+	a.runBroker()
+	a.runRateLimiters()
+	a.runOutboxRelay()
+	a.runStaleUploadsCleaner()
+	a.runUserEventsConsumer()
+
 	closer.Add(func() error {
 		a.grpc.GracefulStop()
 		return nil
@@ -126,6 +179,102 @@ func (a *App) Start() error {
 	return nil
 }
 
+// runBroker получает события чатов от брокера (в том числе из других экземпляров) и раздает их открытым чатам
+func (a *App) runBroker() {
+	ctx, cancel := context.WithCancel(context.Background())
+	closer.Add(func() error {
+		cancel()
+		return nil
+	})
+
+	go func() {
+		err := a.srvProvider.Broker(ctx).Run(ctx, a.chatServer.Dispatch)
+		if err != nil {
+			log.Printf("events broker stopped: %v\n", err)
+		}
+	}()
+}
+
+// runRateLimiters удаляет из памяти корзины пользователей и чатов, которые давно не присылали запросов.
+// В редис корзины истекают сами
+func (a *App) runRateLimiters() {
+	ctx, cancel := context.WithCancel(context.Background())
+	closer.Add(func() error {
+		cancel()
+		return nil
+	})
+
+	for _, limiter := range []rate_limiter.Limiter{a.srvProvider.UserRateLimiter(), a.srvProvider.ChatRateLimiter()} {
+		if keyed, ok := limiter.(*rate_limiter.KeyedLimiter); ok {
+			go keyed.Run(ctx)
+		}
+	}
+}
+
+// runOutboxRelay публикует в kafka события, накопленные в outbox
+func (a *App) runOutboxRelay() {
+	ctx, cancel := context.WithCancel(context.Background())
+	closer.Add(func() error {
+		cancel()
+		return nil
+	})
+
+	go a.srvProvider.OutboxRelay(ctx).Run(ctx)
+}
+
+// runStaleUploadsCleaner удаляет файлы, которые загрузили, но так и не прикрепили к сообщению за UploadTTL
+func (a *App) runStaleUploadsCleaner() {
+	ctx, cancel := context.WithCancel(context.Background())
+	closer.Add(func() error {
+		cancel()
+		return nil
+	})
+	ctx = logger.AssignLogger(ctx, a.srvProvider.Logger())
+
+	go func() {
+		t := time.NewTicker(staleUploadsInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-t.C:
+				// ошибка уже записана в лог, оставшиеся файлы удалятся в следующий раз
+				_, _ = a.srvProvider.ChatService(ctx).DeleteStaleUploads(ctx, now.Add(-a.srvProvider.Config().Attachments.UploadTTL))
+			}
+		}
+	}()
+}
+
+// runUserEventsConsumer читает события пользователей из сервиса auth.
+// Если обработка упала, например недоступна бд, через паузу начинаем заново с последнего непрочитанного сообщения
+func (a *App) runUserEventsConsumer() {
+	ctx, cancel := context.WithCancel(context.Background())
+	closer.Add(func() error {
+		cancel()
+		return nil
+	})
+
+	cons := a.srvProvider.UserEventsConsumer()
+	cons.GroupHandler().SetMessageHandler(a.srvProvider.UserEventsHandler(ctx, a.chatServer).Handle)
+
+	go func() {
+		for {
+			err := cons.RunConsume(ctx, a.srvProvider.Config().UserEvents.Topic)
+			if err == nil || ctx.Err() != nil {
+				return
+			}
+
+			log.Printf("user events consumer failed: %v\n", err)
+			select {
+			case <-time.After(consumerRestartDelay):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
 // StartPrometheusServer запускает сервер prometheus
 func (a *App) StartPrometheusServer() error {
 	log.Printf("Prometheus server started on %s\n", a.srvProvider.Config().Prometheus.Address())
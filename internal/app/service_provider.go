@@ -2,28 +2,60 @@ package app
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"log"
+	"time"
 
+	"github.com/IBM/sarama"
+	redigo "github.com/gomodule/redigo/redis"
+	"github.com/rkchv/chat/lib/blob"
+	"github.com/rkchv/chat/lib/blob/local"
 	"github.com/rkchv/chat/lib/closer"
 	"github.com/rkchv/chat/lib/db"
 	"github.com/rkchv/chat/lib/db/pg"
-	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"github.com/rkchv/chat/lib/kafka"
+	"github.com/rkchv/chat/lib/logger"
+	"github.com/rkchv/chat/lib/rate_limiter"
+	"github.com/rkchv/chat/lib/redis"
+	rediscl "github.com/rkchv/chat/lib/redis/redis"
+	"golang.org/x/exp/slog"
 
 	"github.com/rkchv/chat/internal/config"
-	grpc_client "github.com/rkchv/chat/internal/grpc-client"
+	"github.com/rkchv/chat/internal/consumer"
+	"github.com/rkchv/chat/internal/domain/invite"
+	"github.com/rkchv/chat/internal/domain/message"
+	"github.com/rkchv/chat/internal/grpc-server/streaming"
+	"github.com/rkchv/chat/internal/pubsub"
+	"github.com/rkchv/chat/internal/relay"
 	"github.com/rkchv/chat/internal/repository"
 	"github.com/rkchv/chat/internal/repository/postgres"
+	redisrepo "github.com/rkchv/chat/internal/repository/redis"
 	"github.com/rkchv/chat/internal/services"
 )
 
 type serviceProvider struct {
 	conf           *config.Config
+	logger         *slog.Logger
 	chatService    *services.Service
 	chatRepository repository.Repository
+	msgRepository  repository.MessageRepository
+	outboxRepo     repository.OutboxRepository
+	inviteRepo     repository.InviteRepository
+	presenceRepo   repository.PresenceRepository
+	instanceId     string
+	attachmentRepo repository.AttachmentRepository
+	mentionRepo    repository.MentionRepository
+	blobStore      blob.Store
 	dbc            db.Client
-	authService    *grpc.ClientConn
+	redisPool      *redigo.Pool
+	redisClient    redis.Client
+	broker         pubsub.Broker
+	kafkaProducer  kafka.Producer
+	outboxRelay    *relay.OutboxRelay
+	userConsumer   kafka.Consumer
+	userLimiter    rate_limiter.Limiter
+	chatLimiter    rate_limiter.Limiter
 }
 
 func newServiceProvider() *serviceProvider {
@@ -39,6 +71,14 @@ func (sp *serviceProvider) Config() config.Config {
 	return *sp.conf
 }
 
+func (sp *serviceProvider) Logger() *slog.Logger {
+	if sp.logger == nil {
+		sp.logger = logger.SetupLogger(logger.Env(sp.Config().Env))
+	}
+
+	return sp.logger
+}
+
 func (sp *serviceProvider) DbClient(ctx context.Context) db.Client {
 	if sp.dbc == nil {
 		client, err := pg.NewClient(ctx, sp.Config().Postgres.DSN())
@@ -66,28 +106,240 @@ func (sp *serviceProvider) ChatRepository(ctx context.Context) repository.Reposi
 	return sp.chatRepository
 }
 
+func (sp *serviceProvider) MessageRepository(ctx context.Context) repository.MessageRepository {
+	if sp.msgRepository == nil {
+		sp.msgRepository = postgres.NewMessageRepository(sp.DbClient(ctx), sp.Config().Search.Language)
+	}
+
+	return sp.msgRepository
+}
+
+func (sp *serviceProvider) OutboxRepository(ctx context.Context) repository.OutboxRepository {
+	if sp.outboxRepo == nil {
+		sp.outboxRepo = postgres.NewOutboxRepository(sp.DbClient(ctx))
+	}
+
+	return sp.outboxRepo
+}
+
+func (sp *serviceProvider) InviteRepository(ctx context.Context) repository.InviteRepository {
+	if sp.inviteRepo == nil {
+		sp.inviteRepo = postgres.NewInviteRepository(sp.DbClient(ctx))
+	}
+
+	return sp.inviteRepo
+}
+
+func (sp *serviceProvider) AttachmentRepository(ctx context.Context) repository.AttachmentRepository {
+	if sp.attachmentRepo == nil {
+		sp.attachmentRepo = postgres.NewAttachmentRepository(sp.DbClient(ctx))
+	}
+
+	return sp.attachmentRepo
+}
+
+func (sp *serviceProvider) MentionRepository(ctx context.Context) repository.MentionRepository {
+	if sp.mentionRepo == nil {
+		sp.mentionRepo = postgres.NewMentionRepository(sp.DbClient(ctx))
+	}
+
+	return sp.mentionRepo
+}
+
+func (sp *serviceProvider) BlobStore() blob.Store {
+	if sp.blobStore == nil {
+		store, err := local.NewStore(sp.Config().Attachments.Dir)
+		if err != nil {
+			log.Fatalf("failed to open attachments store: %v", err)
+		}
+		sp.blobStore = store
+	}
+
+	return sp.blobStore
+}
+
+func (sp *serviceProvider) PresenceRepository() repository.PresenceRepository {
+	if sp.presenceRepo == nil {
+		sp.presenceRepo = redisrepo.NewPresenceRepository(sp.RedisClient(), sp.Config().Session.PresenceTTL, sp.InstanceId())
+	}
+
+	return sp.presenceRepo
+}
+
+// InstanceId случайный идентификатор этого экземпляра сервиса, различает отметки присутствия разных экземпляров
+func (sp *serviceProvider) InstanceId() string {
+	if sp.instanceId == "" {
+		b := make([]byte, 8)
+		if _, err := rand.Read(b); err != nil {
+			log.Fatalf("failed to generate instance id: %v", err)
+		}
+		sp.instanceId = hex.EncodeToString(b)
+	}
+
+	return sp.instanceId
+}
+
 func (sp *serviceProvider) ChatService(ctx context.Context) *services.Service {
 	if sp.chatService == nil {
 		sp.chatService = services.NewService(
+			sp.DbClient(ctx).DB(),
 			sp.ChatRepository(ctx),
-			grpc_client.NewAuth(sp.AuthService(ctx)),
+			sp.MessageRepository(ctx),
+			sp.OutboxRepository(ctx),
+			sp.InviteRepository(ctx),
+			sp.PresenceRepository(),
+			sp.AttachmentRepository(ctx),
+			sp.MentionRepository(ctx),
+			sp.BlobStore(),
+			invite.NewSigner([]byte(sp.Config().InviteSecret())),
+			message.AttachmentPolicy{MaxSize: sp.Config().Attachments.MaxSize, MimeTypes: sp.Config().Attachments.MimeTypes},
 		)
 	}
 
 	return sp.chatService
 }
 
-func (sp *serviceProvider) AuthService(_ context.Context) *grpc.ClientConn {
-	if sp.authService == nil {
-		cl, err := grpc.NewClient(sp.Config().AuthServiceAddr,
-			grpc.WithTransportCredentials(insecure.NewCredentials()),
-			grpc.WithStatsHandler(otelgrpc.NewClientHandler()))
+// SubscriberQueue настройки очередей событий подписчиков чатов
+func (sp *serviceProvider) SubscriberQueue() streaming.Queue {
+	cfg := sp.Config().Subscribers
+	policy, err := streaming.ParseSlowPolicy(cfg.SlowPolicy)
+	if err != nil {
+		log.Fatalf("failed to configure subscriber queues: %v", err)
+	}
+
+	return streaming.Queue{Size: cfg.QueueSize, Policy: policy, BlockTimeout: cfg.BlockTimeout}
+}
+
+// UserRateLimiter лимит запросов на пользователя
+func (sp *serviceProvider) UserRateLimiter() rate_limiter.Limiter {
+	if sp.userLimiter == nil {
+		cfg := sp.Config().RateLimit
+		sp.userLimiter = sp.rateLimiter(cfg.UserRequests, cfg.UserPeriod)
+	}
+
+	return sp.userLimiter
+}
+
+// ChatRateLimiter лимит запросов в чат
+func (sp *serviceProvider) ChatRateLimiter() rate_limiter.Limiter {
+	if sp.chatLimiter == nil {
+		cfg := sp.Config().RateLimit
+		sp.chatLimiter = sp.rateLimiter(cfg.ChatRequests, cfg.ChatPeriod)
+	}
+
+	return sp.chatLimiter
+}
+
+// rateLimitPrefix префикс ключей корзин в редис, ключи пользователей и чатов различаются сами
+const rateLimitPrefix = "chat:ratelimit:"
+
+func (sp *serviceProvider) rateLimiter(requests uint, period time.Duration) rate_limiter.Limiter {
+	switch sp.Config().RateLimit.Backend {
+	case "local":
+		return rate_limiter.NewKeyed(requests, period, sp.Config().RateLimit.IdleTTL)
+	case "redis":
+		return rate_limiter.NewRedis(sp.RedisClient(), rateLimitPrefix, requests, period)
+	default:
+		log.Fatalf("unknown rate limit backend: %s", sp.Config().RateLimit.Backend)
+	}
+
+	return nil
+}
+
+func (sp *serviceProvider) RedisPool() *redigo.Pool {
+	if sp.redisPool == nil {
+		sp.redisPool = &redigo.Pool{
+			MaxIdle:     sp.Config().Redis.MaxIdle,
+			IdleTimeout: sp.Config().Redis.IdleTimeout,
+			DialContext: func(ctx context.Context) (redigo.Conn, error) {
+				return redigo.DialContext(ctx, "tcp", sp.Config().Redis.Address(),
+					redigo.DialConnectTimeout(sp.Config().Redis.ConnectionTimeout))
+			},
+		}
+		closer.Add(sp.redisPool.Close)
+	}
+
+	return sp.redisPool
+}
+
+func (sp *serviceProvider) RedisClient() redis.Client {
+	if sp.redisClient == nil {
+		sp.redisClient = rediscl.NewClient(sp.RedisPool())
+	}
+
+	return sp.redisClient
+}
+
+func (sp *serviceProvider) Broker(_ context.Context) pubsub.Broker {
+	if sp.broker == nil {
+		switch sp.Config().EventsBroker {
+		case "local":
+			sp.broker = pubsub.NewLocalBroker()
+		case "redis":
+			sp.broker = pubsub.NewRedisBroker(sp.RedisClient())
+		default:
+			log.Fatalf("unknown events broker: %s", sp.Config().EventsBroker)
+		}
+	}
+
+	return sp.broker
+}
+
+func (sp *serviceProvider) KafkaProducer() kafka.Producer {
+	if sp.kafkaProducer == nil {
+		cfg := sarama.NewConfig()
+		cfg.Producer.RequiredAcks = sarama.WaitForAll
+		cfg.Producer.Idempotent = true
+		cfg.Producer.Return.Successes = true
+		cfg.Net.MaxOpenRequests = 1
+
+		producer, err := kafka.NewProducer(sp.Config().Kafka.Brokers, cfg)
 		if err != nil {
-			log.Fatalf("failed to connect to auth service: %v", err)
+			log.Fatalf("failed to create kafka producer: %v", err)
 		}
 
-		sp.authService = cl
+		sp.kafkaProducer = producer
+		closer.Add(sp.kafkaProducer.Close)
+	}
+
+	return sp.kafkaProducer
+}
+
+func (sp *serviceProvider) OutboxRelay(ctx context.Context) *relay.OutboxRelay {
+	if sp.outboxRelay == nil {
+		sp.outboxRelay = relay.NewOutboxRelay(
+			sp.DbClient(ctx).DB(),
+			sp.OutboxRepository(ctx),
+			sp.KafkaProducer(),
+			sp.Config().Outbox.Interval,
+			sp.Config().Outbox.BatchSize,
+			sp.Config().Outbox.SendTimeout,
+			sp.Config().Outbox.Retention,
+		)
 	}
 
-	return sp.authService
+	return sp.outboxRelay
+}
+
+// UserEventsConsumer получатель событий пользователей из сервиса auth, обработчик назначается при запуске
+func (sp *serviceProvider) UserEventsConsumer() kafka.Consumer {
+	if sp.userConsumer == nil {
+		cfg := sarama.NewConfig()
+		cfg.Consumer.Offsets.Initial = sarama.OffsetOldest
+		cfg.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{sarama.NewBalanceStrategyRoundRobin()}
+
+		cons, err := kafka.NewConsumer(sp.Config().Kafka.Brokers, sp.Config().UserEvents.GroupID, cfg)
+		if err != nil {
+			log.Fatalf("failed to create kafka consumer: %v", err)
+		}
+
+		sp.userConsumer = cons
+		closer.Add(sp.userConsumer.Close)
+	}
+
+	return sp.userConsumer
+}
+
+func (sp *serviceProvider) UserEventsHandler(ctx context.Context, notifier consumer.MembersNotifier) *consumer.UserEventsHandler {
+	return consumer.NewUserEventsHandler(sp.ChatService(ctx), notifier, sp.Logger())
 }
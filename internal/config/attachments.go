@@ -0,0 +1,15 @@
+package config
+
+import "time"
+
+// Attachments ограничения и хранилище файлов, прикрепляемых к сообщениям
+type Attachments struct {
+	// Dir каталог локального хранилища файлов
+	Dir string `yaml:"dir" env:"ATTACHMENTS_DIR" env-default:"./data/attachments"`
+	// MaxSize максимальный размер файла в байтах
+	MaxSize int64 `yaml:"max_size" env:"ATTACHMENTS_MAX_SIZE" env-default:"20971520"`
+	// MimeTypes разрешенные типы содержимого, тип определяется по самому файлу, а не по тому, что прислал клиент
+	MimeTypes []string `yaml:"mime_types" env:"ATTACHMENTS_MIME_TYPES" env-separator:"," env-default:"image/png,image/jpeg,image/gif,image/webp,application/pdf,text/plain"`
+	// UploadTTL сколько хранится файл, который загрузили, но так и не прикрепили к сообщению
+	UploadTTL time.Duration `yaml:"upload_ttl" env:"ATTACHMENTS_UPLOAD_TTL" env-default:"24h"`
+}
@@ -16,11 +16,31 @@ type Config struct {
 	GRPC
 	Postgres
 	SecretKey        string        `yaml:"secret_key" env:"JWT_SECRET_KEY" env-required:"true"`
-	AuthServiceAddr  string        `yaml:"auth_service_addr" env:"AUTH_SERVICE_ADDR" env-default:"localhost:50501"`
-	ChatGarbageCycle time.Duration `yaml:"chat_garbage_cycle" env:"CHAT_GARBAGE_CYCLE" env-default:"10s"`
+	InviteSecretKey  string        `yaml:"invite_secret_key" env:"INVITE_SECRET_KEY"`
 	ChatExpired      time.Duration `yaml:"chat_expired" env:"CHAT_EXPIRED" env-default:"1m"`
+	ChatHistoryLimit uint64        `yaml:"chat_history_limit" env:"CHAT_HISTORY_LIMIT" env-default:"500"`
+	// EventsBroker через что экземпляры сервиса обмениваются событиями чатов: local (один экземпляр) или redis
+	EventsBroker string `yaml:"events_broker" env:"EVENTS_BROKER" env-default:"local"`
 	Trace
 	Prometheus
+	Redis
+	Kafka
+	Outbox
+	UserEvents
+	RateLimit
+	Session
+	Search
+	Attachments
+	Subscribers
+}
+
+// InviteSecret ключ подписи токенов приглашений, если отдельный ключ не задан - используется SecretKey
+func (c Config) InviteSecret() string {
+	if c.InviteSecretKey != "" {
+		return c.InviteSecretKey
+	}
+
+	return c.SecretKey
 }
 
 // MustLoad загружает конфиг из окружения/файла. Фаталится если не получится
@@ -0,0 +1,24 @@
+package config
+
+import "time"
+
+// Kafka настройки подключения к kafka
+type Kafka struct {
+	Brokers []string `yaml:"brokers" env:"KAFKA_BROKERS" env-separator:"," env-default:"localhost:9092"`
+}
+
+// Outbox настройки публикации событий из outbox в kafka
+type Outbox struct {
+	Interval  time.Duration `yaml:"interval" env:"OUTBOX_INTERVAL" env-default:"1s"`
+	BatchSize uint64        `yaml:"batch_size" env:"OUTBOX_BATCH_SIZE" env-default:"100"`
+	// SendTimeout сколько ждать подтверждения kafka, пока открыта транзакция с выбранной пачкой
+	SendTimeout time.Duration `yaml:"send_timeout" env:"OUTBOX_SEND_TIMEOUT" env-default:"10s"`
+	// Retention сколько хранить опубликованные события, потом они удаляются
+	Retention time.Duration `yaml:"retention" env:"OUTBOX_RETENTION" env-default:"168h"`
+}
+
+// UserEvents настройки получения событий пользователей из сервиса auth
+type UserEvents struct {
+	Topic   string `yaml:"topic" env:"AUTH_USER_EVENTS_TOPIC" env-default:"auth.users"`
+	GroupID string `yaml:"group_id" env:"KAFKA_GROUP_ID" env-default:"chat-service"`
+}
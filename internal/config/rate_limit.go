@@ -0,0 +1,15 @@
+package config
+
+import "time"
+
+// RateLimit ограничения частоты запросов: отдельно на пользователя и на чат
+type RateLimit struct {
+	// Backend где хранятся корзины: local (в памяти экземпляра) или redis (общие для всех экземпляров)
+	Backend      string        `yaml:"backend" env:"RATE_LIMIT_BACKEND" env-default:"local"`
+	UserRequests uint          `yaml:"user_requests" env:"RATE_LIMIT_USER_REQUESTS" env-default:"20"`
+	UserPeriod   time.Duration `yaml:"user_period" env:"RATE_LIMIT_USER_PERIOD" env-default:"1s"`
+	ChatRequests uint          `yaml:"chat_requests" env:"RATE_LIMIT_CHAT_REQUESTS" env-default:"100"`
+	ChatPeriod   time.Duration `yaml:"chat_period" env:"RATE_LIMIT_CHAT_PERIOD" env-default:"1s"`
+	// IdleTTL через сколько простоя забывается корзина пользователя или чата в памяти, должно быть больше периодов
+	IdleTTL time.Duration `yaml:"idle_ttl" env:"RATE_LIMIT_IDLE_TTL" env-default:"10m"`
+}
@@ -0,0 +1,20 @@
+package config
+
+import (
+	"net"
+	"time"
+)
+
+// Redis настройки подключения к редис
+type Redis struct {
+	Host              string        `yaml:"host" env:"REDIS_HOST" env-default:"localhost"`
+	Port              string        `yaml:"port" env:"REDIS_PORT" env-default:"6379"`
+	ConnectionTimeout time.Duration `yaml:"connection_timeout" env:"REDIS_CONNECTION_TIMEOUT" env-default:"5s"`
+	MaxIdle           int           `yaml:"max_idle" env:"REDIS_MAX_IDLE" env-default:"10"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"REDIS_IDLE_TIMEOUT" env-default:"5m"`
+}
+
+// Address адрес подключения
+func (r Redis) Address() string {
+	return net.JoinHostPort(r.Host, r.Port)
+}
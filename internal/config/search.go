@@ -0,0 +1,7 @@
+package config
+
+// Search настройки полнотекстового поиска по сообщениям
+type Search struct {
+	// Language конфигурация текстового поиска postgres (russian, english, simple...), с ней индексируются новые сообщения и разбираются запросы
+	Language string `yaml:"language" env:"SEARCH_LANGUAGE" env-default:"russian"`
+}
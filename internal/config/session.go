@@ -0,0 +1,15 @@
+package config
+
+import "time"
+
+// Session настройки двунаправленных сессий чата
+type Session struct {
+	// TypingTTL через сколько набор текста считается законченным, если клиент его не повторил
+	TypingTTL time.Duration `yaml:"typing_ttl" env:"SESSION_TYPING_TTL" env-default:"6s"`
+	// IdleTimeout через сколько молчания клиента (ни heartbeat, ни других сообщений) сессия закрывается
+	IdleTimeout time.Duration `yaml:"idle_timeout" env:"SESSION_IDLE_TIMEOUT" env-default:"1m"`
+	// PresenceTTL через сколько пользователь без подключений и heartbeat пропадает из сети, отметка обновляется втрое чаще
+	PresenceTTL time.Duration `yaml:"presence_ttl" env:"SESSION_PRESENCE_TTL" env-default:"30s"`
+	// MemberCheckInterval как часто подписка перепроверяет, что пользователь еще участник чата и не заблокирован
+	MemberCheckInterval time.Duration `yaml:"member_check_interval" env:"SESSION_MEMBER_CHECK_INTERVAL" env-default:"30s"`
+}
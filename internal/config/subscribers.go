@@ -0,0 +1,13 @@
+package config
+
+import "time"
+
+// Subscribers настройки очередей событий, которые чат держит для каждого подписчика
+type Subscribers struct {
+	// QueueSize сколько событий может ждать отправки одному подписчику
+	QueueSize int `yaml:"queue_size" env:"SUBSCRIBER_QUEUE_SIZE" env-default:"256"`
+	// SlowPolicy что делать, когда очередь подписчика заполнена: drop_oldest, disconnect или block
+	SlowPolicy string `yaml:"slow_policy" env:"SUBSCRIBER_SLOW_POLICY" env-default:"drop_oldest"`
+	// BlockTimeout сколько при политике block события подписчика ждут места в очереди, прежде чем его отключить
+	BlockTimeout time.Duration `yaml:"block_timeout" env:"SUBSCRIBER_BLOCK_TIMEOUT" env-default:"1s"`
+}
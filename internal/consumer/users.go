@@ -0,0 +1,75 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/IBM/sarama"
+	"github.com/rkchv/chat/lib/logger"
+	"golang.org/x/exp/slog"
+
+	"github.com/rkchv/chat/internal/services/models"
+)
+
+// Типы событий пользователей из сервиса auth
+const (
+	UserDeleted = "user.deleted"
+	UserRenamed = "user.renamed"
+)
+
+// UserEvent событие сервиса auth об изменении пользователя
+type UserEvent struct {
+	Type   string `json:"type"`
+	UserId int64  `json:"user_id"`
+	Name   string `json:"name"`
+}
+
+type UserService interface {
+	RemoveUser(ctx context.Context, userId int64) (*models.RemovedUser, error)
+	RenameUser(ctx context.Context, userId int64, name string) error
+}
+
+// MembersNotifier оповещает подключенных клиентов об исключении пользователя из чатов и смене владельцев
+type MembersNotifier interface {
+	UserDeleted(ctx context.Context, userId int64, removed *models.RemovedUser)
+}
+
+// UserEventsHandler обрабатывает события пользователей: удаленных исключает из чатов, переименованным обновляет имя
+type UserEventsHandler struct {
+	service  UserService
+	notifier MembersNotifier
+	log      *slog.Logger
+}
+
+func NewUserEventsHandler(service UserService, notifier MembersNotifier, log *slog.Logger) *UserEventsHandler {
+	return &UserEventsHandler{service: service, notifier: notifier, log: log}
+}
+
+// Handle обработчик для kafka.GroupHandler. Ошибка хранилища возвращается, чтобы сообщение не было помечено прочитанным
+func (h *UserEventsHandler) Handle(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	log := h.log.With(slog.String("topic", msg.Topic), slog.Int64("offset", msg.Offset))
+	ctx = logger.AssignLogger(ctx, log)
+
+	var event UserEvent
+	if err := json.Unmarshal(msg.Value, &event); err != nil {
+		// битое сообщение не исправится при повторе, пропускаем
+		log.Error("failed to unmarshal user event", slog.String("error", err.Error()))
+		return nil
+	}
+
+	switch event.Type {
+	case UserDeleted:
+		removed, err := h.service.RemoveUser(ctx, event.UserId)
+		if err != nil {
+			return err
+		}
+
+		h.notifier.UserDeleted(ctx, event.UserId, removed)
+	case UserRenamed:
+		return h.service.RenameUser(ctx, event.UserId, event.Name)
+	default:
+		log.Debug("skip user event", slog.String("type", event.Type))
+	}
+
+	return nil
+}
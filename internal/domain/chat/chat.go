@@ -1,24 +1,388 @@
 package chat
 
 import (
-	"slices"
+	"errors"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
+const (
+	maxTitleLen       = 255
+	maxDescriptionLen = 2000
+)
+
+var (
+	// ErrDirectMembers в личном чате ровно два участника
+	ErrDirectMembers = errors.New("в личном чате должно быть ровно два участника")
+	// ErrTitleRequired у канала должно быть название
+	ErrTitleRequired = errors.New("не задано название чата")
+	// ErrTitleTooLong слишком длинное название
+	ErrTitleTooLong = errors.New("слишком длинное название чата")
+	// ErrDescriptionTooLong слишком длинное описание
+	ErrDescriptionTooLong = errors.New("слишком длинное описание чата")
+	// ErrUnknownType неизвестный тип чата
+	ErrUnknownType = errors.New("неизвестный тип чата")
+)
+
+// Type тип чата
+type Type string
+
+const (
+	// TypeGroup групповой чат
+	TypeGroup Type = "group"
+	// TypeDirect личная переписка двух пользователей
+	TypeDirect Type = "direct"
+	// TypeChannel канал
+	TypeChannel Type = "channel"
+)
+
+// Member участник чата
+type Member struct {
+	UserId   int64
+	UserName string
+	Role     Role
+	// Muted участнику запрещено писать до MutedUntil, нулевое MutedUntil - бессрочно
+	Muted      bool
+	MutedUntil time.Time
+}
+
+// IsMuted действует ли сейчас запрет писать
+func (m Member) IsMuted(now time.Time) bool {
+	return m.Muted && (m.MutedUntil.IsZero() || now.Before(m.MutedUntil))
+}
+
 type Chat struct {
-	Id        int64
-	UserIds   []int64
-	CreatedAt time.Time
+	Id          int64
+	Title       string
+	Description string
+	Type        Type
+	CreatedBy   int64
+	Members     []Member
+	// PinnedMessageId закрепленное сообщение, 0 если нет
+	PinnedMessageId int64
+	CreatedAt       time.Time
+}
+
+// NewChat создает чат и проверяет его инварианты. Создатель становится владельцем, остальные - обычными участниками
+func NewChat(creator Member, title string, description string, chatType Type, members []Member) (Chat, error) {
+	creator.Role = RoleOwner
+	ch := Chat{
+		Title:       strings.TrimSpace(title),
+		Description: strings.TrimSpace(description),
+		Type:        chatType,
+		CreatedBy:   creator.UserId,
+		CreatedAt:   time.Now(),
+	}
+
+	ch.addMember(creator)
+	for _, m := range members {
+		m.Role = RoleMember
+		ch.addMember(m)
+	}
+
+	if err := ch.validate(); err != nil {
+		return Chat{}, err
+	}
+
+	return ch, nil
+}
+
+func (c *Chat) validate() error {
+	switch c.Type {
+	case TypeGroup:
+	case TypeDirect:
+		if len(c.Members) != 2 {
+			return ErrDirectMembers
+		}
+	case TypeChannel:
+		if c.Title == "" {
+			return ErrTitleRequired
+		}
+	default:
+		return ErrUnknownType
+	}
+
+	if utf8.RuneCountInString(c.Title) > maxTitleLen {
+		return ErrTitleTooLong
+	}
+
+	if utf8.RuneCountInString(c.Description) > maxDescriptionLen {
+		return ErrDescriptionTooLong
+	}
+
+	return nil
+}
+
+// UserIds id всех участников чата
+func (c *Chat) UserIds() []int64 {
+	return UserIds(c.Members)
+}
+
+// UserIds id пользователей из списка участников
+func UserIds(members []Member) []int64 {
+	ids := make([]int64, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.UserId)
+	}
+
+	return ids
+}
+
+// IsMember является ли пользователь участником чата
+func (c *Chat) IsMember(userId int64) bool {
+	_, ok := c.Member(userId)
+	return ok
+}
+
+// Member участник чата по id пользователя
+func (c *Chat) Member(userId int64) (Member, bool) {
+	for _, m := range c.Members {
+		if m.UserId == userId {
+			return m, true
+		}
+	}
+
+	return Member{}, false
+}
+
+// Can разрешено ли пользователю действие в чате, не участникам не разрешено ничего
+func (c *Chat) Can(userId int64, action Action) bool {
+	m, ok := c.Member(userId)
+	return ok && m.Role.Can(action)
+}
+
+// CanSend может ли пользователь сейчас писать в чат
+func (c *Chat) CanSend(userId int64, now time.Time) error {
+	m, ok := c.Member(userId)
+	if !ok {
+		return ErrNotMember
+	}
+
+	if !m.Role.Can(ActionSendMessage) {
+		return ErrPermissionDenied
+	}
+
+	if m.IsMuted(now) {
+		return ErrMuted
+	}
+
+	return nil
+}
+
+// AddMembers добавляет участников в чат обычными участниками, возвращает только тех, кого в чате еще не было.
+// В личный чат новых участников добавить нельзя
+func (c *Chat) AddMembers(by int64, members []Member) ([]Member, error) {
+	if !c.Can(by, ActionManageMembers) {
+		return nil, ErrPermissionDenied
+	}
+
+	added := make([]Member, 0, len(members))
+	for _, m := range members {
+		m.Role = RoleMember
+		joined, err := c.Join(m)
+		if err != nil {
+			return nil, err
+		}
+		if joined {
+			added = append(added, m)
+		}
+	}
+
+	return added, nil
+}
+
+// Join добавляет участника с его ролью без проверки прав, например по приглашению.
+// Возвращает false, если пользователь уже участник
+func (c *Chat) Join(m Member) (bool, error) {
+	if c.IsMember(m.UserId) {
+		return false, nil
+	}
+
+	if c.Type == TypeDirect {
+		return false, ErrDirectMembers
+	}
+
+	c.addMember(m)
+	return true, nil
+}
+
+// CanInvite может ли пользователь приглашать в чат с заданной ролью. Пригласить можно только с ролью младше своей
+func (c *Chat) CanInvite(by int64, role Role) error {
+	if !role.Valid() || role == RoleOwner {
+		return ErrUnknownRole
+	}
+
+	if c.Type == TypeDirect {
+		return ErrDirectMembers
+	}
+
+	actor, _ := c.Member(by)
+	if !actor.Role.Can(ActionManageMembers) || !actor.Role.Outranks(role) {
+		return ErrPermissionDenied
+	}
+
+	return nil
+}
+
+// RemoveMember исключает участника из чата. Исключать можно только участников с младшей ролью,
+// выйти сам может любой, кроме владельца. Состав личного чата не меняется
+func (c *Chat) RemoveMember(by int64, userId int64) error {
+	if c.Type == TypeDirect {
+		return ErrDirectMembers
+	}
+
+	target, ok := c.Member(userId)
+	if !ok {
+		return ErrNotMember
+	}
+
+	if by == userId {
+		if target.Role == RoleOwner {
+			return ErrOwnerLeave
+		}
+	} else {
+		actor, _ := c.Member(by)
+		if !actor.Role.Can(ActionManageMembers) || !actor.Role.Outranks(target.Role) {
+			return ErrPermissionDenied
+		}
+	}
+
+	for i, m := range c.Members {
+		if m.UserId == userId {
+			c.Members = append(c.Members[:i], c.Members[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+// Ban исключает пользователя из чата без права вернуться. Заблокировать можно и того, кто еще не участник.
+// Возвращает true, если пользователь был участником. В личном чате блокировать некого
+func (c *Chat) Ban(by int64, userId int64) (bool, error) {
+	if c.Type == TypeDirect {
+		return false, ErrDirectMembers
+	}
+
+	actor, _ := c.Member(by)
+	if !actor.Role.Can(ActionModerate) {
+		return false, ErrPermissionDenied
+	}
+
+	target, ok := c.Member(userId)
+	if !ok {
+		return false, nil
+	}
+
+	if !actor.Role.Outranks(target.Role) {
+		return false, ErrPermissionDenied
+	}
+
+	for i, m := range c.Members {
+		if m.UserId == userId {
+			c.Members = append(c.Members[:i], c.Members[i+1:]...)
+			break
+		}
+	}
+
+	return true, nil
+}
+
+// Mute запрещает участнику писать до until, нулевое until - бессрочно
+func (c *Chat) Mute(by int64, userId int64, until time.Time) error {
+	if err := c.canModerate(by, userId); err != nil {
+		return err
+	}
+
+	c.setMute(userId, true, until)
+
+	return nil
+}
+
+// Unmute снимает запрет писать
+func (c *Chat) Unmute(by int64, userId int64) error {
+	if err := c.canModerate(by, userId); err != nil {
+		return err
+	}
+
+	c.setMute(userId, false, time.Time{})
+
+	return nil
+}
+
+func (c *Chat) canModerate(by int64, userId int64) error {
+	actor, _ := c.Member(by)
+	target, ok := c.Member(userId)
+	if !ok {
+		return ErrNotMember
+	}
+
+	if !actor.Role.Can(ActionModerate) || !actor.Role.Outranks(target.Role) {
+		return ErrPermissionDenied
+	}
+
+	return nil
+}
+
+func (c *Chat) setMute(userId int64, muted bool, until time.Time) {
+	for i := range c.Members {
+		if c.Members[i].UserId == userId {
+			c.Members[i].Muted = muted
+			c.Members[i].MutedUntil = until
+		}
+	}
+}
+
+// SetRole меняет роль участника. И старая, и новая роль должны быть младше роли того, кто меняет.
+// Владелец так не назначается, для этого есть TransferOwnership
+func (c *Chat) SetRole(by int64, userId int64, role Role) error {
+	if !role.Valid() || role == RoleOwner {
+		return ErrUnknownRole
+	}
+
+	actor, _ := c.Member(by)
+	target, ok := c.Member(userId)
+	if !ok {
+		return ErrNotMember
+	}
+
+	if !actor.Role.Can(ActionManageRoles) || !actor.Role.Outranks(target.Role) || !actor.Role.Outranks(role) {
+		return ErrPermissionDenied
+	}
+
+	c.setRole(userId, role)
+
+	return nil
+}
+
+// TransferOwnership передает владение чатом другому участнику, прежний владелец становится администратором
+func (c *Chat) TransferOwnership(by int64, userId int64) error {
+	actor, _ := c.Member(by)
+	if actor.Role != RoleOwner {
+		return ErrPermissionDenied
+	}
+
+	if !c.IsMember(userId) {
+		return ErrNotMember
+	}
+
+	c.setRole(by, RoleAdmin)
+	c.setRole(userId, RoleOwner)
+
+	return nil
 }
 
-func NewChat() Chat {
-	return Chat{
-		CreatedAt: time.Now(),
+func (c *Chat) setRole(userId int64, role Role) {
+	for i := range c.Members {
+		if c.Members[i].UserId == userId {
+			c.Members[i].Role = role
+		}
 	}
 }
 
-func (c *Chat) Connect(userId int64) {
-	if !slices.Contains(c.UserIds, userId) {
-		c.UserIds = append(c.UserIds, userId)
+func (c *Chat) addMember(m Member) {
+	if !c.IsMember(m.UserId) {
+		c.Members = append(c.Members, m)
 	}
 }
@@ -0,0 +1,84 @@
+package chat
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestChatCanSend(t *testing.T) {
+	now := time.Now()
+	ch := Chat{
+		Members: []Member{
+			{UserId: 1, Role: RoleOwner},
+			{UserId: 2, Role: RoleMember},
+			{UserId: 3, Role: RoleReadonly},
+			{UserId: 4, Role: RoleMember, Muted: true},
+			{UserId: 5, Role: RoleMember, Muted: true, MutedUntil: now.Add(time.Hour)},
+			{UserId: 6, Role: RoleMember, Muted: true, MutedUntil: now.Add(-time.Hour)},
+			{UserId: 7, Role: RoleAdmin, Muted: true},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		userId int64
+		err    error
+	}{
+		{name: "владелец", userId: 1},
+		{name: "участник", userId: 2},
+		{name: "не участник", userId: 100, err: ErrNotMember},
+		{name: "только чтение", userId: 3, err: ErrPermissionDenied},
+		{name: "бессрочный запрет", userId: 4, err: ErrMuted},
+		{name: "запрет еще действует", userId: 5, err: ErrMuted},
+		{name: "запрет истек", userId: 6},
+		{name: "запрет действует и на администратора", userId: 7, err: ErrMuted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ch.CanSend(tt.userId, now); !errors.Is(err, tt.err) {
+				t.Fatalf("CanSend = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestChatDirectMembersAreFixed(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(c *Chat) error
+	}{
+		{name: "добавить", change: func(c *Chat) error {
+			_, err := c.AddMembers(1, []Member{{UserId: 3}})
+			return err
+		}},
+		{name: "пригласить", change: func(c *Chat) error { return c.CanInvite(1, RoleMember) }},
+		{name: "исключить", change: func(c *Chat) error { return c.RemoveMember(1, 2) }},
+		{name: "выйти", change: func(c *Chat) error { return c.RemoveMember(2, 2) }},
+		{name: "заблокировать участника", change: func(c *Chat) error {
+			_, err := c.Ban(1, 2)
+			return err
+		}},
+		{name: "заблокировать постороннего", change: func(c *Chat) error {
+			_, err := c.Ban(1, 3)
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Chat{
+				Type:    TypeDirect,
+				Members: []Member{{UserId: 1, Role: RoleOwner}, {UserId: 2, Role: RoleOwner}},
+			}
+
+			if err := tt.change(c); !errors.Is(err, ErrDirectMembers) {
+				t.Fatalf("err = %v, want %v", err, ErrDirectMembers)
+			}
+			if len(c.Members) != 2 {
+				t.Fatalf("direct chat has %d members after rejected change", len(c.Members))
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package chat
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/rkchv/chat/internal/domain/message"
+)
+
+// Mentions сопоставляет @имя из текста с участниками чата по имени без учета регистра, @all - со всеми участниками.
+// Возвращает упоминания для сохранения в сообщении и получателей упоминания - участников без автора
+func (c *Chat) Mentions(authorId int64, text string) ([]message.Mention, []int64) {
+	var (
+		mentions   []message.Mention
+		recipients []int64
+	)
+
+	for _, token := range message.ParseMentions(text) {
+		if strings.EqualFold(token.Name, message.MentionAll) {
+			mentions = append(mentions, message.Mention{All: true, Offset: token.Offset, Length: token.Length})
+			for _, m := range c.Members {
+				recipients = append(recipients, m.UserId)
+			}
+			continue
+		}
+
+		for _, m := range c.Members {
+			if strings.EqualFold(m.UserName, token.Name) {
+				mentions = append(mentions, message.Mention{UserId: m.UserId, Offset: token.Offset, Length: token.Length})
+				recipients = append(recipients, m.UserId)
+				break
+			}
+		}
+	}
+
+	recipients = slices.DeleteFunc(recipients, func(id int64) bool { return id == authorId })
+	slices.Sort(recipients)
+
+	return mentions, slices.Compact(recipients)
+}
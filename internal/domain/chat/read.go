@@ -0,0 +1,12 @@
+package chat
+
+import "time"
+
+// ReadPosition до какого сообщения участник прочитал чат
+type ReadPosition struct {
+	UserId   int64
+	UserName string
+	// MessageId последнее прочитанное сообщение, все более ранние тоже считаются прочитанными
+	MessageId int64
+	ReadAt    time.Time
+}
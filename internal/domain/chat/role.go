@@ -0,0 +1,87 @@
+package chat
+
+import "errors"
+
+var (
+	// ErrPermissionDenied у пользователя нет прав на действие в чате
+	ErrPermissionDenied = errors.New("недостаточно прав в чате")
+	// ErrUnknownRole неизвестная роль участника
+	ErrUnknownRole = errors.New("неизвестная роль участника")
+	// ErrOwnerLeave владелец не может выйти из чата, пока не передаст владение
+	ErrOwnerLeave = errors.New("владелец должен передать владение чатом перед выходом")
+	// ErrNotMember пользователь не участник чата
+	ErrNotMember = errors.New("пользователь не участник чата")
+	// ErrMuted пользователю запрещено писать в чат
+	ErrMuted = errors.New("пользователю запрещено писать в чат")
+	// ErrBanned пользователь заблокирован в чате
+	ErrBanned = errors.New("пользователь заблокирован в чате")
+)
+
+// Role роль участника в чате
+type Role string
+
+const (
+	// RoleOwner владелец, в чате он один
+	RoleOwner Role = "owner"
+	// RoleAdmin администратор
+	RoleAdmin Role = "admin"
+	// RoleMember обычный участник
+	RoleMember Role = "member"
+	// RoleReadonly участник, который может только читать
+	RoleReadonly Role = "readonly"
+)
+
+// Action действие в чате, на которое проверяются права
+type Action int
+
+const (
+	// ActionSendMessage отправка сообщений
+	ActionSendMessage Action = iota
+	// ActionPinMessage закрепление сообщений
+	ActionPinMessage
+	// ActionModerate удаление чужих сообщений
+	ActionModerate
+	// ActionManageMembers добавление и исключение участников
+	ActionManageMembers
+	// ActionManageRoles изменение ролей участников
+	ActionManageRoles
+	// ActionDeleteChat удаление чата
+	ActionDeleteChat
+)
+
+var permissions = map[Role][]Action{
+	RoleOwner:    {ActionSendMessage, ActionPinMessage, ActionModerate, ActionManageMembers, ActionManageRoles, ActionDeleteChat},
+	RoleAdmin:    {ActionSendMessage, ActionPinMessage, ActionModerate, ActionManageMembers, ActionManageRoles},
+	RoleMember:   {ActionSendMessage},
+	RoleReadonly: {},
+}
+
+// rank старшинство ролей, действовать можно только в отношении участников с младшей ролью
+var rank = map[Role]int{
+	RoleReadonly: 1,
+	RoleMember:   2,
+	RoleAdmin:    3,
+	RoleOwner:    4,
+}
+
+// Valid известная ли роль
+func (r Role) Valid() bool {
+	_, ok := rank[r]
+	return ok
+}
+
+// Can разрешено ли роли действие
+func (r Role) Can(action Action) bool {
+	for _, a := range permissions[r] {
+		if a == action {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Outranks старше ли роль другой
+func (r Role) Outranks(other Role) bool {
+	return rank[r] > rank[other]
+}
@@ -0,0 +1,54 @@
+package chat
+
+import "testing"
+
+func TestRoleOutranks(t *testing.T) {
+	tests := []struct {
+		role  Role
+		other Role
+		want  bool
+	}{
+		{role: RoleOwner, other: RoleAdmin, want: true},
+		{role: RoleAdmin, other: RoleMember, want: true},
+		{role: RoleMember, other: RoleReadonly, want: true},
+		{role: RoleOwner, other: RoleReadonly, want: true},
+		{role: RoleAdmin, other: RoleAdmin, want: false},
+		{role: RoleMember, other: RoleAdmin, want: false},
+		{role: RoleAdmin, other: RoleOwner, want: false},
+		{role: RoleReadonly, other: Role("unknown"), want: true},
+		{role: Role("unknown"), other: RoleReadonly, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role)+">"+string(tt.other), func(t *testing.T) {
+			if got := tt.role.Outranks(tt.other); got != tt.want {
+				t.Fatalf("Outranks = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoleCan(t *testing.T) {
+	tests := []struct {
+		name   string
+		role   Role
+		action Action
+		want   bool
+	}{
+		{name: "владелец удаляет чат", role: RoleOwner, action: ActionDeleteChat, want: true},
+		{name: "администратор не удаляет чат", role: RoleAdmin, action: ActionDeleteChat, want: false},
+		{name: "администратор меняет роли", role: RoleAdmin, action: ActionManageRoles, want: true},
+		{name: "участник пишет", role: RoleMember, action: ActionSendMessage, want: true},
+		{name: "участник не закрепляет", role: RoleMember, action: ActionPinMessage, want: false},
+		{name: "читатель не пишет", role: RoleReadonly, action: ActionSendMessage, want: false},
+		{name: "неизвестная роль ничего не может", role: Role("unknown"), action: ActionSendMessage, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.role.Can(tt.action); got != tt.want {
+				t.Fatalf("Can = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package chat
+
+import (
+	"time"
+
+	"github.com/rkchv/chat/internal/domain/message"
+)
+
+// Summary чат в списке чатов пользователя
+type Summary struct {
+	Id          int64
+	Title       string
+	Type        Type
+	MemberCount int
+	// LastMessage последнее неудаленное сообщение, nil если сообщений нет
+	LastMessage *message.Message
+	// UnreadCount сколько чужих сообщений пользователь еще не прочитал
+	UnreadCount int
+	// ActivityAt время последнего сообщения, а если их нет - создания чата
+	ActivityAt time.Time
+}
@@ -0,0 +1,78 @@
+package invite
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/rkchv/chat/internal/domain/chat"
+)
+
+const codeLen = 16
+
+var (
+	// ErrRevoked приглашение отозвано
+	ErrRevoked = errors.New("приглашение отозвано")
+	// ErrExpired срок действия приглашения истек
+	ErrExpired = errors.New("срок действия приглашения истек")
+	// ErrUsedUp приглашение использовано максимальное число раз
+	ErrUsedUp = errors.New("приглашение больше нельзя использовать")
+)
+
+// Invite приглашение в чат. Code случайный идентификатор, по которому приглашение ищется при входе,
+// пользователям выдается подписанный токен с ним (см. Signer)
+type Invite struct {
+	Id        int64
+	Code      string
+	ChatId    int64
+	CreatedBy int64
+	// Role роль, которую получает вступивший по приглашению
+	Role chat.Role
+	// MaxUses сколько раз можно использовать, 0 - без ограничений
+	MaxUses int
+	Uses    int
+	// ExpiresAt до какого момента действует, нулевое значение - бессрочно
+	ExpiresAt time.Time
+	RevokedAt time.Time
+	CreatedAt time.Time
+}
+
+func NewInvite(chatId int64, createdBy int64, role chat.Role, maxUses int, expiresAt time.Time) (Invite, error) {
+	code := make([]byte, codeLen)
+	if _, err := rand.Read(code); err != nil {
+		return Invite{}, err
+	}
+
+	return Invite{
+		Code:      base64.RawURLEncoding.EncodeToString(code),
+		ChatId:    chatId,
+		CreatedBy: createdBy,
+		Role:      role,
+		MaxUses:   maxUses,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// Use проверяет, что приглашение еще действует, и засчитывает использование
+func (i *Invite) Use(now time.Time) error {
+	switch {
+	case !i.RevokedAt.IsZero():
+		return ErrRevoked
+	case !i.ExpiresAt.IsZero() && !now.Before(i.ExpiresAt):
+		return ErrExpired
+	case i.MaxUses > 0 && i.Uses >= i.MaxUses:
+		return ErrUsedUp
+	}
+
+	i.Uses++
+	return nil
+}
+
+// Revoke отзывает приглашение, повторный отзыв ничего не меняет
+func (i *Invite) Revoke(now time.Time) {
+	if i.RevokedAt.IsZero() {
+		i.RevokedAt = now
+	}
+}
@@ -0,0 +1,38 @@
+package invite
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestInviteUse(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		invite Invite
+		err    error
+		uses   int
+	}{
+		{name: "без ограничений", invite: Invite{Uses: 100}, uses: 101},
+		{name: "последнее использование", invite: Invite{MaxUses: 3, Uses: 2}, uses: 3},
+		{name: "использовано максимальное число раз", invite: Invite{MaxUses: 3, Uses: 3}, err: ErrUsedUp, uses: 3},
+		{name: "еще действует", invite: Invite{ExpiresAt: now.Add(time.Minute)}, uses: 1},
+		{name: "истекло ровно сейчас", invite: Invite{ExpiresAt: now}, err: ErrExpired},
+		{name: "отозвано", invite: Invite{RevokedAt: now.Add(-time.Minute)}, err: ErrRevoked},
+		{name: "отзыв важнее срока и лимита", invite: Invite{MaxUses: 1, Uses: 1, ExpiresAt: now.Add(-time.Minute), RevokedAt: now}, err: ErrRevoked, uses: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := tt.invite
+			if err := inv.Use(now); !errors.Is(err, tt.err) {
+				t.Fatalf("Use = %v, want %v", err, tt.err)
+			}
+			if inv.Uses != tt.uses {
+				t.Fatalf("uses = %d, want %d", inv.Uses, tt.uses)
+			}
+		})
+	}
+}
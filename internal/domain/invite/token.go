@@ -0,0 +1,48 @@
+package invite
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidToken токен приглашения поврежден или подписан другим ключом
+var ErrInvalidToken = errors.New("некорректный токен приглашения")
+
+// Signer выдает и проверяет токены приглашений. Токен - код приглашения и его подпись HMAC-SHA256,
+// поддельные токены отсекаются без обращения к базе
+type Signer struct {
+	key []byte
+}
+
+func NewSigner(key []byte) *Signer {
+	return &Signer{key: key}
+}
+
+// Token подписанный токен для кода приглашения
+func (s *Signer) Token(code string) string {
+	return code + "." + base64.RawURLEncoding.EncodeToString(s.sign(code))
+}
+
+// Code проверяет подпись токена и возвращает код приглашения
+func (s *Signer) Code(token string) (string, error) {
+	code, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", ErrInvalidToken
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(raw, s.sign(code)) {
+		return "", ErrInvalidToken
+	}
+
+	return code, nil
+}
+
+func (s *Signer) sign(code string) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(code))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,41 @@
+package invite
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSignerCode(t *testing.T) {
+	signer := NewSigner([]byte("secret"))
+	token := signer.Token("abc123")
+
+	code, sig, _ := strings.Cut(token, ".")
+
+	tests := []struct {
+		name  string
+		token string
+		code  string
+		err   error
+	}{
+		{name: "подписанный токен", token: token, code: "abc123"},
+		{name: "подменен код", token: "abc124." + sig, err: ErrInvalidToken},
+		{name: "подменена подпись", token: code + "." + strings.Repeat("A", len(sig)), err: ErrInvalidToken},
+		{name: "подписан другим ключом", token: NewSigner([]byte("other")).Token("abc123"), err: ErrInvalidToken},
+		{name: "нет подписи", token: "abc123", err: ErrInvalidToken},
+		{name: "подпись не base64", token: code + ".!!!", err: ErrInvalidToken},
+		{name: "пустой токен", token: "", err: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := signer.Code(tt.token)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if code != tt.code {
+				t.Fatalf("code = %q, want %q", code, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package message
+
+import (
+	"errors"
+	"mime"
+	"slices"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxAttachments сколько вложений можно прикрепить к одному сообщению
+const MaxAttachments = 10
+
+const maxFileNameLen = 255
+
+var (
+	// ErrTooLarge файл больше допустимого размера
+	ErrTooLarge = errors.New("файл слишком большой")
+	// ErrMimeType тип файла не разрешен
+	ErrMimeType = errors.New("недопустимый тип файла")
+	// ErrFileName некорректное имя файла
+	ErrFileName = errors.New("некорректное имя файла")
+	// ErrAttachment вложение нельзя прикрепить: оно из другого чата, загружено другим пользователем или уже прикреплено
+	ErrAttachment = errors.New("вложение недоступно")
+	// ErrTooManyAttachments слишком много вложений в одном сообщении
+	ErrTooManyAttachments = errors.New("слишком много вложений")
+)
+
+// Attachment загруженный в чат файл. Пока MessageId 0, файл загружен, но еще не прикреплен к сообщению
+type Attachment struct {
+	Id         int64
+	ChatId     int64
+	MessageId  int64
+	UploadedBy int64
+	// BlobKey ключ содержимого в хранилище файлов
+	BlobKey   string
+	FileName  string
+	MimeType  string
+	Size      int64
+	CreatedAt time.Time
+}
+
+// AttachmentPolicy ограничения на загружаемые файлы
+type AttachmentPolicy struct {
+	MaxSize   int64
+	MimeTypes []string
+}
+
+// CheckName проверяет имя файла, оно только показывается клиентам и в пути хранилища не участвует
+func (p AttachmentPolicy) CheckName(name string) error {
+	if name == "" || !utf8.ValidString(name) || utf8.RuneCountInString(name) > maxFileNameLen {
+		return ErrFileName
+	}
+
+	return nil
+}
+
+// CheckType проверяет, что тип содержимого разрешен. Параметры типа (charset и т.п.) не учитываются
+func (p AttachmentPolicy) CheckType(contentType string) error {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !slices.Contains(p.MimeTypes, mediaType) {
+		return ErrMimeType
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package message
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MentionAll упоминание всех участников чата: @all
+const MentionAll = "all"
+
+// mentionRe @имя в начале текста или после символа, который не может быть частью имени или адреса почты
+var mentionRe = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_.@])@([\p{L}\p{N}_][\p{L}\p{N}_.\-]*)`)
+
+// Mention упоминание в тексте сообщения. Offset и Length считаются в символах (рунах), а не в байтах
+type Mention struct {
+	// UserId упомянутый участник, 0 для @all
+	UserId int64
+	All    bool
+	Offset int
+	Length int
+}
+
+// MentionToken @имя, найденное в тексте, еще не сопоставленное с участником
+type MentionToken struct {
+	Name   string
+	Offset int
+	Length int
+}
+
+// ParseMentions находит в тексте все @имя. Точка и дефис в конце имени считаются знаками препинания
+func ParseMentions(text string) []MentionToken {
+	var tokens []MentionToken
+	for _, match := range mentionRe.FindAllStringSubmatchIndex(text, -1) {
+		name := strings.TrimRight(text[match[2]:match[3]], ".-")
+		// позиция @ перед именем
+		start := match[2] - 1
+		tokens = append(tokens, MentionToken{
+			Name:   name,
+			Offset: utf8.RuneCountInString(text[:start]),
+			Length: utf8.RuneCountInString(name) + 1,
+		})
+	}
+
+	return tokens
+}
+
+// MentionItem сообщение в ленте упоминаний пользователя
+type MentionItem struct {
+	Message *Message
+	// ReadAt когда пользователь прочитал упоминание, нулевое если еще не прочитал
+	ReadAt time.Time
+}
@@ -0,0 +1,37 @@
+package message
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []MentionToken
+	}{
+		{name: "без упоминаний", text: "привет всем", want: nil},
+		{name: "в начале текста", text: "@bob привет", want: []MentionToken{{Name: "bob", Offset: 0, Length: 4}}},
+		{name: "@all", text: "внимание @all", want: []MentionToken{{Name: "all", Offset: 9, Length: 4}}},
+		{name: "смещение в символах, а не байтах", text: "привет @вася", want: []MentionToken{{Name: "вася", Offset: 7, Length: 5}}},
+		{name: "точка в конце - знак препинания", text: "спроси @alice.", want: []MentionToken{{Name: "alice", Offset: 7, Length: 6}}},
+		{name: "точка внутри имени", text: "@john.doe, привет", want: []MentionToken{{Name: "john.doe", Offset: 0, Length: 9}}},
+		{name: "в скобках", text: "(@bob)", want: []MentionToken{{Name: "bob", Offset: 1, Length: 4}}},
+		{name: "адрес почты не упоминание", text: "пиши на bob@example.com", want: nil},
+		{name: "одинокая @", text: "@ и @-", want: nil},
+		{
+			name: "несколько упоминаний",
+			text: "@bob и @alice",
+			want: []MentionToken{{Name: "bob", Offset: 0, Length: 4}, {Name: "alice", Offset: 7, Length: 6}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseMentions(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParseMentions(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
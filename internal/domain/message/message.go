@@ -0,0 +1,75 @@
+package message
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotAuthor изменять сообщение может только его автор
+	ErrNotAuthor = errors.New("недостаточно прав на изменение сообщения")
+	// ErrDeleted сообщение уже удалено
+	ErrDeleted = errors.New("сообщение удалено")
+)
+
+type Message struct {
+	Id        int64
+	ChatId    int64
+	UserId    int64
+	Text      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt time.Time
+	// Attachments прикрепленные файлы, заполняются при чтении сообщений из хранилища
+	Attachments []Attachment
+	Mentions    []Mention
+}
+
+func NewMessage(chatId int64, userId int64, text string) Message {
+	return Message{
+		ChatId:    chatId,
+		UserId:    userId,
+		Text:      text,
+		CreatedAt: time.Now(),
+	}
+}
+
+// IsDeleted Удалено ли сообщение
+func (m *Message) IsDeleted() bool {
+	return !m.DeletedAt.IsZero()
+}
+
+// Edit Меняет текст сообщения, возвращает ревизию с прежним текстом
+func (m *Message) Edit(userId int64, text string) (Revision, error) {
+	if m.IsDeleted() {
+		return Revision{}, ErrDeleted
+	}
+
+	if m.UserId != userId {
+		return Revision{}, ErrNotAuthor
+	}
+
+	rev := newRevision(m, ActionEdit, userId)
+	m.Text = text
+	m.UpdatedAt = rev.CreatedAt
+
+	return rev, nil
+}
+
+// Delete Помечает сообщение удаленным. Удалить может автор или администратор чата
+func (m *Message) Delete(userId int64, isAdmin bool) (Revision, error) {
+	if m.IsDeleted() {
+		return Revision{}, ErrDeleted
+	}
+
+	if m.UserId != userId && !isAdmin {
+		return Revision{}, ErrNotAuthor
+	}
+
+	rev := newRevision(m, ActionDelete, userId)
+	m.Text = ""
+	m.UpdatedAt = rev.CreatedAt
+	m.DeletedAt = rev.CreatedAt
+
+	return rev, nil
+}
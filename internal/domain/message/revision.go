@@ -0,0 +1,31 @@
+package message
+
+import "time"
+
+type Action string
+
+const (
+	ActionEdit   Action = "edit"
+	ActionDelete Action = "delete"
+)
+
+// Revision состояние сообщения до изменения
+type Revision struct {
+	Id        int64
+	MessageId int64
+	Action    Action
+	// Text текст сообщения до изменения
+	Text      string
+	ChangedBy int64
+	CreatedAt time.Time
+}
+
+func newRevision(m *Message, action Action, userId int64) Revision {
+	return Revision{
+		MessageId: m.Id,
+		Action:    action,
+		Text:      m.Text,
+		ChangedBy: userId,
+		CreatedAt: time.Now(),
+	}
+}
@@ -0,0 +1,13 @@
+package message
+
+// Совпадения в SearchHit.Snippet выделяются этими маркерами
+const (
+	HighlightStart = "**"
+	HighlightStop  = "**"
+)
+
+// SearchHit сообщение, найденное поиском, и фрагмент его текста с выделенными совпадениями
+type SearchHit struct {
+	Message *Message
+	Snippet string
+}
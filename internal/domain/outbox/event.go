@@ -0,0 +1,61 @@
+package outbox
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Топики, в которые публикуются события чатов
+const (
+	TopicChats    = "chat.chats"
+	TopicMembers  = "chat.members"
+	TopicMessages = "chat.messages"
+)
+
+// Типы событий
+const (
+	ChatCreated    = "chat.created"
+	ChatDeleted    = "chat.deleted"
+	MemberJoined   = "member.joined"
+	MemberRemoved  = "member.removed"
+	MemberLeft     = "member.left"
+	MemberRole     = "member.role_changed"
+	MemberBanned   = "member.banned"
+	MemberUnbanned = "member.unbanned"
+	MemberMuted    = "member.muted"
+	MemberUnmuted  = "member.unmuted"
+	MessageCreated = "message.created"
+	MessageEdited  = "message.edited"
+	MessageDeleted = "message.deleted"
+	MessagePinned  = "message.pinned"
+)
+
+// Event событие, которое пишется в outbox в одной транзакции с изменением и позже публикуется в kafka
+type Event struct {
+	Id    int64
+	Topic string
+	// Key ключ партиционирования, события одного чата идут по порядку
+	Key       string
+	Type      string
+	Payload   []byte
+	CreatedAt time.Time
+	// Seq номер события среди событий с тем же ключом, назначается при добавлении в outbox
+	Seq int64
+}
+
+// NewChatEvent Создает событие чата, ключом служит id чата
+func NewChatEvent(topic string, eventType string, chatId int64, payload any) (Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		Topic:     topic,
+		Key:       strconv.FormatInt(chatId, 10),
+		Type:      eventType,
+		Payload:   data,
+		CreatedAt: time.Now(),
+	}, nil
+}
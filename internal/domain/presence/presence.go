@@ -0,0 +1,14 @@
+package presence
+
+import "time"
+
+// MaxUsers сколько пользователей можно запросить за раз
+const MaxUsers = 100
+
+// Presence присутствие пользователя в сети
+type Presence struct {
+	UserId int64
+	Online bool
+	// LastSeen когда пользователь последний раз был в сети, нулевое если ни разу
+	LastSeen time.Time
+}
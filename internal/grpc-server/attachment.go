@@ -0,0 +1,98 @@
+package grpc_server
+
+import (
+	"errors"
+	"io"
+
+	"github.com/rkchv/auth/pkg/user_v1/auth"
+	syserr "github.com/rkchv/chat/lib/error"
+
+	"github.com/rkchv/chat/internal/services/models"
+	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
+)
+
+// downloadChunkSize размер частей, которыми файл отдается клиенту
+const downloadChunkSize = 64 * 1024
+
+// UploadAttachment принимает файл частями и сохраняет его, не держа целиком в памяти
+func (s *Server) UploadAttachment(stream chatdesc.ChatV1_UploadAttachmentServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	meta := req.GetMeta()
+	if meta == nil {
+		return syserr.New("Загрузка должна начинаться с meta", syserr.InvalidArgument)
+	}
+
+	tokenUser := auth.UserFromContext(stream.Context())
+	a, err := s.chatService.UploadAttachment(stream.Context(), models.UploadAttachment{
+		ChatId:   meta.GetChatId(),
+		UserId:   tokenUser.ID,
+		FileName: meta.GetFileName(),
+		Content:  &uploadReader{stream: stream},
+	})
+	if err != nil {
+		return err
+	}
+
+	return stream.SendAndClose(toAttachmentDesc(a))
+}
+
+// DownloadAttachment отдает описание файла и затем его содержимое
+func (s *Server) DownloadAttachment(req *chatdesc.DownloadAttachmentRequest, stream chatdesc.ChatV1_DownloadAttachmentServer) error {
+	tokenUser := auth.UserFromContext(stream.Context())
+	a, content, err := s.chatService.OpenAttachment(stream.Context(), req.GetAttachmentId(), tokenUser.ID)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	err = stream.Send(&chatdesc.AttachmentChunk{Chunk: &chatdesc.AttachmentChunk_Info{Info: toAttachmentDesc(a)}})
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, downloadChunkSize)
+	for {
+		n, err := content.Read(buf)
+		if n > 0 {
+			if sendErr := stream.Send(&chatdesc.AttachmentChunk{Chunk: &chatdesc.AttachmentChunk_Data{Data: buf[:n]}}); sendErr != nil {
+				return sendErr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// uploadReader читает содержимое файла из сообщений стрима загрузки, конец стрима - конец файла
+type uploadReader struct {
+	stream chatdesc.ChatV1_UploadAttachmentServer
+	buf    []byte
+}
+
+func (r *uploadReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		req, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+
+		chunk, ok := req.GetRequest().(*chatdesc.UploadAttachmentRequest_Chunk)
+		if !ok {
+			return 0, syserr.New("После meta ожидается только содержимое файла", syserr.InvalidArgument)
+		}
+		r.buf = chunk.Chunk
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+
+	return n, nil
+}
@@ -1,36 +1,133 @@
 package grpc_server
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	"github.com/rkchv/auth/pkg/user_v1/auth"
 	syserr "github.com/rkchv/chat/lib/error"
 
+	"github.com/rkchv/chat/internal/domain/message"
+	"github.com/rkchv/chat/internal/grpc-server/streaming"
 	"github.com/rkchv/chat/internal/services/models"
-	userdesc "github.com/rkchv/chat/pkg/chat_v1"
+	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
 )
 
-// Connect подключает пользователя к чату
-func (s *Server) Connect(req *userdesc.ConnectRequest, stream userdesc.ChatV1_ConnectServer) error {
-	s.m.RLock()
-	existChat, ok := s.connectedChats[req.GetChatId()]
-	s.m.RUnlock()
-	if !ok {
-		return syserr.New("Чат не найден", syserr.NotFound)
-	}
+// Connect подключает пользователя к чату, стрим только с сообщениями для старых клиентов
+func (s *Server) Connect(req *chatdesc.ConnectRequest, stream chatdesc.ChatV1_ConnectServer) error {
+	return s.subscribe(req, streaming.NewMessageStream(stream), nil)
+}
+
+// Subscribe подключает пользователя к чату и рассылает ему все события чата
+func (s *Server) Subscribe(req *chatdesc.ConnectRequest, stream chatdesc.ChatV1_SubscribeServer) error {
+	return s.subscribe(req, stream, nil)
+}
 
+// subscribe подключает стрим участника к чату. Если задан since, сначала досылает историю, потом живые события.
+// connected, если задан, вызывается, когда права проверены и стрим уже подключен к чату
+func (s *Server) subscribe(req *chatdesc.ConnectRequest, stream streaming.Stream, connected func()) error {
 	tokenUser := auth.UserFromContext(stream.Context())
-	err := s.chatService.Connect(stream.Context(), models.Connect{ChatId: req.GetChatId(), UserId: tokenUser.ID})
+	member := models.Connect{ChatId: req.GetChatId(), UserId: tokenUser.ID}
+	err := s.chatService.Connect(stream.Context(), member)
+	if err != nil {
+		return err
+	}
+
+	// чат мог быть создан в другом экземпляре или закрыт за простоем, тогда открываем его здесь
+	existChat, err := s.OpenChat(stream.Context(), req.GetChatId())
 	if err != nil {
 		return err
 	}
 
-	existChat.Connect(tokenUser.ID, stream)
+	// подключаемся к чату до выборки истории, чтобы не потерять сообщения, пришедшие во время выборки
+	replayStream := streaming.NewReplayStream(stream)
+	wasOnline := existChat.Online(tokenUser.ID)
+	connID, kicked := existChat.Connect(tokenUser.ID, replayStream)
 	s.metrics.IncreaseClients()
-	for {
-		select {
-		case <-stream.Context().Done():
-			existChat.Disconnect(tokenUser.ID)
-			s.metrics.DecreaseClients()
-			return stream.Context().Err()
+	// в сети пользователь, пока у него есть хоть одно подключение к чату
+	if !wasOnline {
+		s.broadcast(stream.Context(), presenceEvent(req.GetChatId(), tokenUser.ID, true))
+	}
+
+	presenceCtx, stopPresence := context.WithCancel(stream.Context())
+	defer stopPresence()
+	go s.keepOnline(presenceCtx, tokenUser.ID)
+	memberLost := s.watchMembership(presenceCtx, member)
+	if connected != nil {
+		connected()
+	}
+
+	defer func() {
+		existChat.Disconnect(connID)
+		s.metrics.DecreaseClients()
+		if !existChat.Online(tokenUser.ID) {
+			s.broadcast(context.WithoutCancel(stream.Context()), presenceEvent(req.GetChatId(), tokenUser.ID, false))
 		}
+	}()
+
+	err = s.replayHistory(req, replayStream)
+	if err != nil {
+		return err
 	}
+
+	select {
+	case <-stream.Context().Done():
+		return context.Cause(stream.Context())
+	case <-existChat.Done():
+		return nil
+	case err = <-memberLost:
+		return err
+	case reason := <-kicked:
+		if errors.Is(reason, streaming.ErrSlowConsumer) {
+			return syserr.New("Клиент не успевает получать события чата", syserr.ResourceExhausted)
+		}
+
+		return syserr.New("Пользователь больше не участник чата", syserr.PermissionDenied)
+	}
+}
+
+// watchMembership периодически перепроверяет, что пользователь все еще участник чата и не заблокирован.
+// Событие о выходе рассылается через брокер без гарантий доставки, и если оно потерялось, исключенного
+// или заблокированного пользователя отключит эта проверка. Временные ошибки проверки подписку не закрывают
+func (s *Server) watchMembership(ctx context.Context, member models.Connect) <-chan error {
+	lost := make(chan error, 1)
+	go func() {
+		t := time.NewTicker(s.session.MemberCheckInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				err := s.chatService.Connect(ctx, member)
+				if e := syserr.GetCommonError(err); e != nil && (e.Code() == syserr.PermissionDenied || e.Code() == syserr.NotFound) {
+					lost <- err
+					return
+				}
+			}
+		}
+	}()
+
+	return lost
+}
+
+func (s *Server) replayHistory(req *chatdesc.ConnectRequest, stream *streaming.ReplayStream) error {
+	if req.GetSince() == nil {
+		return stream.Replay(nil)
+	}
+
+	history := models.History{ChatId: req.GetChatId(), SinceId: req.GetSinceId(), Limit: s.historyLimit}
+	if req.GetSinceTime() != nil {
+		history.Since = req.GetSinceTime().AsTime()
+	}
+
+	err := s.chatService.History(stream.Context(), history, func(messages []*message.Message) error {
+		return stream.SendHistory(historyEvents(messages))
+	})
+	if err != nil {
+		return err
+	}
+
+	return stream.Replay(nil)
 }
@@ -0,0 +1,195 @@
+package grpc_server
+
+import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/rkchv/chat/internal/domain/chat"
+	"github.com/rkchv/chat/internal/domain/message"
+	"github.com/rkchv/chat/internal/domain/presence"
+	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
+)
+
+func toMessageDesc(msg *message.Message) *chatdesc.Message {
+	res := &chatdesc.Message{
+		Id:        msg.Id,
+		From:      msg.UserId,
+		Text:      msg.Text,
+		Timestamp: timestamppb.New(msg.CreatedAt),
+		Type:      chatdesc.EventType_EVENT_TYPE_CREATED,
+	}
+
+	if !msg.UpdatedAt.IsZero() {
+		res.Type = chatdesc.EventType_EVENT_TYPE_EDITED
+		res.EditedAt = timestamppb.New(msg.UpdatedAt)
+	}
+
+	if msg.IsDeleted() {
+		res.Type = chatdesc.EventType_EVENT_TYPE_DELETED
+	}
+
+	for i := range msg.Attachments {
+		res.Attachments = append(res.Attachments, toAttachmentDesc(&msg.Attachments[i]))
+	}
+
+	for _, m := range msg.Mentions {
+		res.Mentions = append(res.Mentions, &chatdesc.Mention{
+			UserId: m.UserId,
+			All:    m.All,
+			Offset: int32(m.Offset),
+			Length: int32(m.Length),
+		})
+	}
+
+	return res
+}
+
+func toAttachmentDesc(a *message.Attachment) *chatdesc.Attachment {
+	return &chatdesc.Attachment{
+		Id:       a.Id,
+		FileName: a.FileName,
+		MimeType: a.MimeType,
+		Size:     a.Size,
+	}
+}
+
+func toMessagesDesc(messages []*message.Message) []*chatdesc.Message {
+	res := make([]*chatdesc.Message, 0, len(messages))
+	for _, msg := range messages {
+		res = append(res, toMessageDesc(msg))
+	}
+
+	return res
+}
+
+var chatTypes = map[chatdesc.ChatType]chat.Type{
+	chatdesc.ChatType_CHAT_TYPE_GROUP:   chat.TypeGroup,
+	chatdesc.ChatType_CHAT_TYPE_DIRECT:  chat.TypeDirect,
+	chatdesc.ChatType_CHAT_TYPE_CHANNEL: chat.TypeChannel,
+}
+
+// toChatType неизвестный тип остается пустым, его отклонит проверка при создании чата
+func toChatType(t chatdesc.ChatType) chat.Type {
+	return chatTypes[t]
+}
+
+func toMembers(members []*chatdesc.Member) []chat.Member {
+	res := make([]chat.Member, 0, len(members))
+	for _, m := range members {
+		res = append(res, chat.Member{UserId: m.GetUserId(), UserName: m.GetUserName()})
+	}
+
+	return res
+}
+
+func toMembersDesc(members []chat.Member) []*chatdesc.Member {
+	res := make([]*chatdesc.Member, 0, len(members))
+	for _, m := range members {
+		res = append(res, &chatdesc.Member{UserId: m.UserId, UserName: m.UserName, Role: toRoleDesc(m.Role)})
+	}
+
+	return res
+}
+
+var roles = map[chatdesc.MemberRole]chat.Role{
+	chatdesc.MemberRole_MEMBER_ROLE_MEMBER:   chat.RoleMember,
+	chatdesc.MemberRole_MEMBER_ROLE_READONLY: chat.RoleReadonly,
+	chatdesc.MemberRole_MEMBER_ROLE_ADMIN:    chat.RoleAdmin,
+	chatdesc.MemberRole_MEMBER_ROLE_OWNER:    chat.RoleOwner,
+}
+
+// toRole неизвестная роль остается пустой, ее отклонит проверка в домене
+func toRole(r chatdesc.MemberRole) chat.Role {
+	return roles[r]
+}
+
+func toRoleDesc(r chat.Role) chatdesc.MemberRole {
+	for desc, role := range roles {
+		if role == r {
+			return desc
+		}
+	}
+
+	return chatdesc.MemberRole_MEMBER_ROLE_MEMBER
+}
+
+// previewLen сколько символов последнего сообщения показывать в списке чатов
+const previewLen = 100
+
+func toChatSummariesDesc(chats []*chat.Summary) []*chatdesc.ChatSummary {
+	res := make([]*chatdesc.ChatSummary, 0, len(chats))
+	for _, c := range chats {
+		summary := &chatdesc.ChatSummary{
+			Id:           c.Id,
+			Title:        c.Title,
+			Type:         toChatTypeDesc(c.Type),
+			MemberCount:  int32(c.MemberCount),
+			UnreadCount:  int64(c.UnreadCount),
+			LastActivity: timestamppb.New(c.ActivityAt),
+		}
+		if c.LastMessage != nil {
+			summary.LastMessage = toMessageDesc(c.LastMessage)
+			summary.LastMessage.Text = preview(c.LastMessage.Text)
+		}
+		res = append(res, summary)
+	}
+
+	return res
+}
+
+func toChatTypeDesc(t chat.Type) chatdesc.ChatType {
+	for desc, chatType := range chatTypes {
+		if chatType == t {
+			return desc
+		}
+	}
+
+	return chatdesc.ChatType_CHAT_TYPE_GROUP
+}
+
+func preview(text string) string {
+	runes := []rune(text)
+	if len(runes) <= previewLen {
+		return text
+	}
+
+	return string(runes[:previewLen]) + "…"
+}
+
+func toPresenceDesc(users []presence.Presence) []*chatdesc.UserPresence {
+	res := make([]*chatdesc.UserPresence, 0, len(users))
+	for _, p := range users {
+		desc := &chatdesc.UserPresence{UserId: p.UserId, Online: p.Online}
+		if !p.LastSeen.IsZero() {
+			desc.LastSeen = timestamppb.New(p.LastSeen)
+		}
+		res = append(res, desc)
+	}
+
+	return res
+}
+
+func toSearchHitsDesc(hits []*message.SearchHit) []*chatdesc.SearchHit {
+	res := make([]*chatdesc.SearchHit, 0, len(hits))
+	for _, hit := range hits {
+		res = append(res, &chatdesc.SearchHit{
+			ChatId:  hit.Message.ChatId,
+			Message: toMessageDesc(hit.Message),
+			Snippet: hit.Snippet,
+		})
+	}
+
+	return res
+}
+
+func toMentionItemsDesc(items []*message.MentionItem) []*chatdesc.MentionItem {
+	res := make([]*chatdesc.MentionItem, 0, len(items))
+	for _, item := range items {
+		res = append(res, &chatdesc.MentionItem{
+			ChatId:  item.Message.ChatId,
+			Message: toMessageDesc(item.Message),
+			Read:    !item.ReadAt.IsZero(),
+		})
+	}
+
+	return res
+}
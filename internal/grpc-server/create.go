@@ -2,42 +2,26 @@ package grpc_server
 
 import (
 	"context"
-	"time"
 
-	"google.golang.org/protobuf/types/known/emptypb"
+	"github.com/rkchv/auth/pkg/user_v1/auth"
 
-	userdesc "github.com/rkchv/chat/pkg/chat_v1"
+	"github.com/rkchv/chat/internal/services/models"
+	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
 )
 
-// Create создает чат
-func (s *Server) Create(ctx context.Context, _ *emptypb.Empty) (*userdesc.CreateResponse, error) {
-	id, err := s.chatService.Create(ctx)
+// Create создает чат, создатель становится его участником
+func (s *Server) Create(ctx context.Context, req *chatdesc.CreateRequest) (*chatdesc.CreateResponse, error) {
+	tokenUser := auth.UserFromContext(ctx)
+	ch, err := s.chatService.Create(ctx, models.Create{
+		CreatorId:   tokenUser.ID,
+		Title:       req.GetTitle(),
+		Description: req.GetDescription(),
+		Type:        toChatType(req.GetType()),
+		Members:     toMembers(req.GetMembers()),
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	newChat := s.NewChat(id)
-
-	go func() {
-		lastNonEmpty := time.Now()
-		t := time.NewTicker(s.garbageCycle)
-		defer t.Stop()
-		for {
-			select {
-			case <-t.C:
-				if newChat.IsEmpty() {
-					if time.Since(lastNonEmpty) > s.chatExpiration {
-						s.CloseChat(newChat)
-						return
-					}
-				} else {
-					lastNonEmpty = time.Now()
-				}
-			default:
-				newChat.BroadcastMessages()
-			}
-		}
-	}()
-
-	return &userdesc.CreateResponse{Id: id}, nil
+	return &chatdesc.CreateResponse{Id: ch.Id}, nil
 }
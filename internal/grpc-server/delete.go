@@ -10,12 +10,13 @@ import (
 
 // Delete удаляет чат
 func (s *Server) Delete(ctx context.Context, req *userdesc.DeleteRequest) (*emptypb.Empty, error) {
-	s.CloseChatByID(req.GetId())
-
 	err := s.chatService.Delete(ctx, req.GetId())
 	if err != nil {
 		return nil, err
 	}
 
+	// экземпляры, где чат открыт, закроют его получив это событие
+	s.broadcast(ctx, chatClosedEvent(req.GetId()))
+
 	return &emptypb.Empty{}, nil
 }
@@ -0,0 +1,49 @@
+package grpc_server
+
+import (
+	"context"
+
+	"github.com/rkchv/auth/pkg/user_v1/auth"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/rkchv/chat/internal/services/models"
+	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
+)
+
+// EditMessage меняет текст сообщения и рассылает изменение в чат
+func (s *Server) EditMessage(ctx context.Context, req *chatdesc.EditMessageRequest) (*chatdesc.Message, error) {
+	tokenUser := auth.UserFromContext(ctx)
+	msg, mentioned, err := s.chatService.EditMessage(ctx, models.EditMessage{
+		ChatId:    req.GetChatId(),
+		MessageId: req.GetMessageId(),
+		UserId:    tokenUser.ID,
+		Text:      req.GetText(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.broadcast(ctx, messageEvent(msg))
+	if len(mentioned) > 0 {
+		s.broadcast(ctx, mentionedEvent(msg, mentioned))
+	}
+
+	return toMessageDesc(msg), nil
+}
+
+// DeleteMessage удаляет сообщение и рассылает удаление в чат
+func (s *Server) DeleteMessage(ctx context.Context, req *chatdesc.DeleteMessageRequest) (*emptypb.Empty, error) {
+	tokenUser := auth.UserFromContext(ctx)
+	msg, err := s.chatService.DeleteMessage(ctx, models.DeleteMessage{
+		ChatId:    req.GetChatId(),
+		MessageId: req.GetMessageId(),
+		UserId:    tokenUser.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.broadcast(ctx, messageEvent(msg))
+
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,121 @@
+package grpc_server
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/rkchv/chat/internal/domain/chat"
+	"github.com/rkchv/chat/internal/domain/message"
+	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
+)
+
+// eventsVersion версия формата ChatEvent
+const eventsVersion = 1
+
+func newEvent(chatId int64) *chatdesc.ChatEvent {
+	return &chatdesc.ChatEvent{
+		Version:   eventsVersion,
+		ChatId:    chatId,
+		Timestamp: timestamppb.New(time.Now()),
+	}
+}
+
+// messageEvent событие о сообщении в зависимости от его состояния: новое, измененное или удаленное
+func messageEvent(msg *message.Message) *chatdesc.ChatEvent {
+	event := newEvent(msg.ChatId)
+	switch {
+	case msg.IsDeleted():
+		event.Timestamp = timestamppb.New(msg.DeletedAt)
+		event.Event = &chatdesc.ChatEvent_MessageDeleted{MessageDeleted: &chatdesc.MessageDeleted{MessageId: msg.Id}}
+	case !msg.UpdatedAt.IsZero():
+		event.Timestamp = timestamppb.New(msg.UpdatedAt)
+		event.Event = &chatdesc.ChatEvent_MessageEdited{MessageEdited: toMessageDesc(msg)}
+	default:
+		event.Timestamp = timestamppb.New(msg.CreatedAt)
+		event.Event = &chatdesc.ChatEvent_MessageCreated{MessageCreated: toMessageDesc(msg)}
+	}
+
+	return event
+}
+
+// historyEvents сохраненные сообщения в виде событий о новых сообщениях
+func historyEvents(messages []*message.Message) []*chatdesc.ChatEvent {
+	events := make([]*chatdesc.ChatEvent, 0, len(messages))
+	for _, msg := range messages {
+		event := newEvent(msg.ChatId)
+		event.Timestamp = timestamppb.New(msg.CreatedAt)
+		event.Event = &chatdesc.ChatEvent_MessageCreated{MessageCreated: toMessageDesc(msg)}
+		events = append(events, event)
+	}
+
+	return events
+}
+
+func memberJoinedEvent(chatId int64, userId int64) *chatdesc.ChatEvent {
+	event := newEvent(chatId)
+	event.Event = &chatdesc.ChatEvent_MemberJoined{MemberJoined: &chatdesc.MemberJoined{UserId: userId}}
+	return event
+}
+
+func memberLeftEvent(chatId int64, userId int64, reason chatdesc.MemberLeftReason) *chatdesc.ChatEvent {
+	event := newEvent(chatId)
+	event.Event = &chatdesc.ChatEvent_MemberLeft{MemberLeft: &chatdesc.MemberLeft{UserId: userId, Reason: reason}}
+	return event
+}
+
+func chatClosedEvent(chatId int64) *chatdesc.ChatEvent {
+	event := newEvent(chatId)
+	event.Event = &chatdesc.ChatEvent_ChatClosed{ChatClosed: &chatdesc.ChatClosed{}}
+	return event
+}
+
+func memberRoleChangedEvent(chatId int64, userId int64, role chatdesc.MemberRole) *chatdesc.ChatEvent {
+	event := newEvent(chatId)
+	event.Event = &chatdesc.ChatEvent_MemberRoleChanged{MemberRoleChanged: &chatdesc.MemberRoleChanged{UserId: userId, Role: role}}
+	return event
+}
+
+func messagePinnedEvent(chatId int64, messageId int64) *chatdesc.ChatEvent {
+	event := newEvent(chatId)
+	event.Event = &chatdesc.ChatEvent_MessagePinned{MessagePinned: &chatdesc.MessagePinned{MessageId: messageId}}
+	return event
+}
+
+func memberMutedEvent(chatId int64, userId int64, muted bool, until time.Time) *chatdesc.ChatEvent {
+	event := newEvent(chatId)
+	mute := &chatdesc.MemberMuted{UserId: userId, Muted: muted}
+	if !until.IsZero() {
+		mute.Until = timestamppb.New(until)
+	}
+	event.Event = &chatdesc.ChatEvent_MemberMuted{MemberMuted: mute}
+	return event
+}
+
+func readPositionEvent(chatId int64, position chat.ReadPosition) *chatdesc.ChatEvent {
+	event := newEvent(chatId)
+	event.Event = &chatdesc.ChatEvent_ReadPosition{ReadPosition: &chatdesc.ReadPosition{
+		UserId:    position.UserId,
+		MessageId: position.MessageId,
+		ReadAt:    timestamppb.New(position.ReadAt),
+	}}
+	return event
+}
+
+func typingEvent(chatId int64, userId int64, typing bool) *chatdesc.ChatEvent {
+	event := newEvent(chatId)
+	event.Event = &chatdesc.ChatEvent_Typing{Typing: &chatdesc.Typing{UserId: userId, Typing: typing}}
+	return event
+}
+
+func presenceEvent(chatId int64, userId int64, online bool) *chatdesc.ChatEvent {
+	event := newEvent(chatId)
+	event.Event = &chatdesc.ChatEvent_Presence{Presence: &chatdesc.Presence{UserId: userId, Online: online}}
+	return event
+}
+
+func mentionedEvent(msg *message.Message, userIds []int64) *chatdesc.ChatEvent {
+	event := newEvent(msg.ChatId)
+	event.Event = &chatdesc.ChatEvent_Mentioned{Mentioned: &chatdesc.Mentioned{MessageId: msg.Id, From: msg.UserId, UserIds: userIds}}
+	return event
+}
@@ -17,6 +17,12 @@ func NewLoggerInterceptor(l *slog.Logger) grpc.UnaryServerInterceptor {
 	return loggerInterceptor
 }
 
+// NewStreamLoggerInterceptor навешивает логгер на контекст стримов
+func NewStreamLoggerInterceptor(l *slog.Logger) grpc.StreamServerInterceptor {
+	lg = l
+	return streamLoggerInterceptor
+}
+
 func loggerInterceptor(ctx context.Context, req interface{}, i *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 	log := lg
 
@@ -28,3 +34,15 @@ func loggerInterceptor(ctx context.Context, req interface{}, i *grpc.UnaryServer
 
 	return handler(ctx, req)
 }
+
+func streamLoggerInterceptor(srv any, ss grpc.ServerStream, i *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx := ss.Context()
+
+	traceId := trace.SpanFromContext(ctx).SpanContext().TraceID().String()
+	log := lg.With(slog.String("trace_id", traceId))
+
+	ctx = logger.AssignLogger(ctx, log)
+	log.Debug("stream opened", slog.String("method", i.FullMethod))
+
+	return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+}
@@ -0,0 +1,141 @@
+package interceptors
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/rkchv/auth/pkg/user_v1/auth"
+	"github.com/rkchv/chat/lib/logger"
+	"github.com/rkchv/chat/lib/rate_limiter"
+	"golang.org/x/exp/slog"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
+)
+
+// chatRequest запрос, относящийся к конкретному чату
+type chatRequest interface {
+	GetChatId() int64
+}
+
+// chunkMessage сообщение стрима с очередной частью данных, например файла. Лимит платит только открывающее
+// сообщение загрузки, иначе большой файл упирался бы в лимит запросов на середине
+type chunkMessage interface {
+	GetChunk() []byte
+}
+
+// sessionSignal служебное сообщение открытой сессии: heartbeat или набор текста. Клиент шлет их постоянно,
+// лимит запросов на них не тратится
+func sessionSignal(m any) bool {
+	req, ok := m.(*chatdesc.SessionRequest)
+	return ok && (req.GetHeartbeat() != nil || req.GetTyping() != nil)
+}
+
+// RateLimit ограничивает частоту запросов каждого пользователя и запросов в каждый чат.
+// Должен идти после проверки доступа, чтобы в контексте уже был пользователь
+type RateLimit struct {
+	users rate_limiter.Limiter
+	chats rate_limiter.Limiter
+}
+
+func NewRateLimit(users rate_limiter.Limiter, chats rate_limiter.Limiter) *RateLimit {
+	return &RateLimit{users: users, chats: chats}
+}
+
+// Unary перехватчик для обычных запросов
+func (r *RateLimit) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := r.check(ctx, req); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// Stream перехватчик для стримов, лимит проверяется на каждое входящее сообщение стрима, кроме частей данных и
+// служебных сообщений сессии. Открывающее сообщение сверх лимита отклоняет стрим, следующие лишние сообщения
+// пропускаются без ответа, чтобы не рвать открытый стрим. У серверных стримов сообщение одно - запрос на открытие
+func (r *RateLimit) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &rateLimitedStream{ServerStream: ss, limit: r})
+	}
+}
+
+func (r *RateLimit) check(ctx context.Context, req interface{}) error {
+	if user := auth.UserFromContext(ctx); user.ID != 0 {
+		if err := r.allow(ctx, r.users, "user:"+strconv.FormatInt(user.ID, 10)); err != nil {
+			return err
+		}
+	}
+
+	if chatReq, ok := req.(chatRequest); ok && chatReq.GetChatId() != 0 {
+		return r.allow(ctx, r.chats, "chat:"+strconv.FormatInt(chatReq.GetChatId(), 10))
+	}
+
+	return nil
+}
+
+func (r *RateLimit) allow(ctx context.Context, limiter rate_limiter.Limiter, key string) error {
+	allowed, retryAfter, err := limiter.Allow(ctx, key)
+	if err != nil {
+		// недоступность хранилища лимитов не должна останавливать сервис, пропускаем запрос
+		logger.GetLogger(ctx).Error("rate limiter failed", slog.String("error", err.Error()), slog.String("key", key))
+		return nil
+	}
+
+	if allowed {
+		return nil
+	}
+
+	return resourceExhausted(retryAfter)
+}
+
+// resourceExhausted ошибка с подсказкой клиенту, через сколько повторить запрос
+func resourceExhausted(retryAfter time.Duration) error {
+	st := status.New(codes.ResourceExhausted, "слишком много запросов")
+	withDetails, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+	if err != nil {
+		return st.Err()
+	}
+
+	return withDetails.Err()
+}
+
+type rateLimitedStream struct {
+	grpc.ServerStream
+	limit *RateLimit
+	// opened открывающее сообщение уже принято
+	opened bool
+}
+
+func (s *rateLimitedStream) RecvMsg(m any) error {
+	for {
+		if err := s.ServerStream.RecvMsg(m); err != nil {
+			return err
+		}
+
+		if chunk, ok := m.(chunkMessage); ok && chunk.GetChunk() != nil {
+			return nil
+		}
+		if sessionSignal(m) {
+			return nil
+		}
+
+		err := s.limit.check(s.Context(), m)
+		if !s.opened {
+			s.opened = err == nil
+			return err
+		}
+		if err == nil {
+			return nil
+		}
+
+		logger.GetLogger(s.Context()).Debug("stream message dropped by rate limit", slog.String("error", err.Error()))
+	}
+}
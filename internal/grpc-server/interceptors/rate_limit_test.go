@@ -0,0 +1,130 @@
+package interceptors
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/rkchv/auth/pkg/user_v1/auth"
+	"github.com/rkchv/chat/lib/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
+)
+
+// fakeLimiter разрешает первые allowed запросов
+type fakeLimiter struct {
+	allowed int
+	calls   int
+}
+
+func (l *fakeLimiter) Allow(_ context.Context, _ string) (bool, time.Duration, error) {
+	l.calls++
+	return l.calls <= l.allowed, time.Second, nil
+}
+
+// fakeStream отдает сообщения клиента по очереди, затем io.EOF
+type fakeStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	messages []*chatdesc.SessionRequest
+}
+
+func (s *fakeStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeStream) RecvMsg(m any) error {
+	if len(s.messages) == 0 {
+		return io.EOF
+	}
+
+	proto.Merge(m.(*chatdesc.SessionRequest), s.messages[0])
+	s.messages = s.messages[1:]
+
+	return nil
+}
+
+func openRequest() *chatdesc.SessionRequest {
+	return &chatdesc.SessionRequest{Request: &chatdesc.SessionRequest_Open{Open: &chatdesc.ConnectRequest{ChatId: 1}}}
+}
+
+func heartbeatRequest() *chatdesc.SessionRequest {
+	return &chatdesc.SessionRequest{Request: &chatdesc.SessionRequest_Heartbeat{Heartbeat: &chatdesc.Heartbeat{}}}
+}
+
+func typingRequest() *chatdesc.SessionRequest {
+	return &chatdesc.SessionRequest{Request: &chatdesc.SessionRequest_Typing{Typing: &chatdesc.SessionTyping{Typing: true}}}
+}
+
+func TestRateLimitedStream(t *testing.T) {
+	tests := []struct {
+		name     string
+		allowed  int
+		messages []*chatdesc.SessionRequest
+		// received сколько сообщений дошло до обработчика
+		received int
+		code     codes.Code
+		calls    int
+	}{
+		{
+			name:     "служебные сообщения сессии не тратят лимит",
+			allowed:  1,
+			messages: []*chatdesc.SessionRequest{openRequest(), heartbeatRequest(), typingRequest(), heartbeatRequest()},
+			received: 4,
+			calls:    1,
+		},
+		{
+			name:     "открывающее сообщение сверх лимита отклоняет стрим",
+			allowed:  0,
+			messages: []*chatdesc.SessionRequest{openRequest(), heartbeatRequest()},
+			code:     codes.ResourceExhausted,
+			calls:    1,
+		},
+		{
+			name:     "лишнее сообщение открытого стрима пропускается",
+			allowed:  1,
+			messages: []*chatdesc.SessionRequest{openRequest(), openRequest(), heartbeatRequest()},
+			received: 2,
+			calls:    2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := &fakeLimiter{allowed: tt.allowed}
+			ctx := logger.AssignLogger(context.Background(), logger.SetupLogger(logger.Disable))
+			ctx = auth.AddUserToContext(ctx, auth.UserClaims{ID: 10})
+			stream := &rateLimitedStream{
+				ServerStream: &fakeStream{ctx: ctx, messages: tt.messages},
+				limit:        NewRateLimit(limiter, &fakeLimiter{allowed: 100}),
+			}
+
+			received := 0
+			var err error
+			for {
+				if err = stream.RecvMsg(&chatdesc.SessionRequest{}); err != nil {
+					break
+				}
+				received++
+			}
+
+			if err != io.EOF && status.Code(err) != tt.code {
+				t.Fatalf("err = %v, want code %v", err, tt.code)
+			}
+			if err == io.EOF && tt.code != codes.OK {
+				t.Fatalf("stream was not rejected, want code %v", tt.code)
+			}
+			if received != tt.received {
+				t.Fatalf("received %d messages, want %d", received, tt.received)
+			}
+			if limiter.calls != tt.calls {
+				t.Fatalf("limiter was called %d times, want %d", limiter.calls, tt.calls)
+			}
+		})
+	}
+}
@@ -23,6 +23,11 @@ type serverStream struct {
 	ctx context.Context
 }
 
+// Context подменяет контекст стрима, чтобы обработчик видел данные, добавленные перехватчиками
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
 // NewStreamAccessInterceptor для заданных методов проверяет наличие access-токена и наличие соответствующего scope в нем
 // так же при успешной проверке записывает данные из токена в контекст
 func NewStreamAccessInterceptor(secureMethods []string, jwtSecretKey string) grpc.StreamServerInterceptor {
@@ -0,0 +1,62 @@
+package grpc_server
+
+import (
+	"context"
+
+	"github.com/rkchv/auth/pkg/user_v1/auth"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/rkchv/chat/internal/services/models"
+	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
+)
+
+// CreateInvite создает приглашение в чат
+func (s *Server) CreateInvite(ctx context.Context, req *chatdesc.CreateInviteRequest) (*chatdesc.CreateInviteResponse, error) {
+	tokenUser := auth.UserFromContext(ctx)
+	invite := models.CreateInvite{
+		ChatId:  req.GetChatId(),
+		UserId:  tokenUser.ID,
+		Role:    toRole(req.GetRole()),
+		MaxUses: int(req.GetMaxUses()),
+	}
+	if req.GetExpiresAt() != nil {
+		invite.ExpiresAt = req.GetExpiresAt().AsTime()
+	}
+
+	inv, token, err := s.chatService.CreateInvite(ctx, invite)
+	if err != nil {
+		return nil, err
+	}
+
+	return &chatdesc.CreateInviteResponse{Id: inv.Id, Token: token}, nil
+}
+
+// RevokeInvite отзывает приглашение
+func (s *Server) RevokeInvite(ctx context.Context, req *chatdesc.RevokeInviteRequest) (*emptypb.Empty, error) {
+	tokenUser := auth.UserFromContext(ctx)
+	err := s.chatService.RevokeInvite(ctx, req.GetId(), tokenUser.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// JoinByInvite вступление в чат по приглашению
+func (s *Server) JoinByInvite(ctx context.Context, req *chatdesc.JoinByInviteRequest) (*chatdesc.JoinByInviteResponse, error) {
+	tokenUser := auth.UserFromContext(ctx)
+	ch, joined, err := s.chatService.JoinByInvite(ctx, models.JoinByInvite{
+		Token:    req.GetToken(),
+		UserId:   tokenUser.ID,
+		UserName: req.GetUserName(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if joined {
+		s.broadcast(ctx, memberJoinedEvent(ch.Id, tokenUser.ID))
+	}
+
+	return &chatdesc.JoinByInviteResponse{ChatId: ch.Id}, nil
+}
@@ -0,0 +1,97 @@
+package grpc_server
+
+import (
+	"context"
+
+	"github.com/rkchv/auth/pkg/user_v1/auth"
+
+	"github.com/rkchv/chat/internal/services/models"
+	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
+)
+
+// ListMessages постранично отдает историю чата
+func (s *Server) ListMessages(ctx context.Context, req *chatdesc.ListMessagesRequest) (*chatdesc.ListMessagesResponse, error) {
+	tokenUser := auth.UserFromContext(ctx)
+	list := models.ListMessages{
+		ChatId:   req.GetChatId(),
+		UserId:   tokenUser.ID,
+		Cursor:   req.GetCursor(),
+		Limit:    uint64(req.GetLimit()),
+		Newer:    req.GetDirection() == chatdesc.Direction_DIRECTION_NEWER,
+		SenderId: req.GetSenderId(),
+	}
+	if req.GetFrom() != nil {
+		list.From = req.GetFrom().AsTime()
+	}
+	if req.GetTo() != nil {
+		list.To = req.GetTo().AsTime()
+	}
+
+	page, err := s.chatService.ListMessages(ctx, list)
+	if err != nil {
+		return nil, err
+	}
+
+	return &chatdesc.ListMessagesResponse{
+		Messages:   toMessagesDesc(page.Messages),
+		NextCursor: page.NextCursor,
+	}, nil
+}
+
+// ListChats постранично отдает чаты пользователя
+func (s *Server) ListChats(ctx context.Context, req *chatdesc.ListChatsRequest) (*chatdesc.ListChatsResponse, error) {
+	tokenUser := auth.UserFromContext(ctx)
+	page, err := s.chatService.ListChats(ctx, models.ListChats{
+		UserId: tokenUser.ID,
+		Cursor: req.GetCursor(),
+		Limit:  uint64(req.GetLimit()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &chatdesc.ListChatsResponse{
+		Chats:      toChatSummariesDesc(page.Chats),
+		NextCursor: page.NextCursor,
+	}, nil
+}
+
+// SearchMessages ищет сообщения по тексту в чатах пользователя
+func (s *Server) SearchMessages(ctx context.Context, req *chatdesc.SearchMessagesRequest) (*chatdesc.SearchMessagesResponse, error) {
+	tokenUser := auth.UserFromContext(ctx)
+	page, err := s.chatService.SearchMessages(ctx, models.SearchMessages{
+		UserId: tokenUser.ID,
+		Query:  req.GetQuery(),
+		ChatId: req.GetChatId(),
+		Cursor: req.GetCursor(),
+		Limit:  uint64(req.GetLimit()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &chatdesc.SearchMessagesResponse{
+		Hits:       toSearchHitsDesc(page.Hits),
+		NextCursor: page.NextCursor,
+	}, nil
+}
+
+// ListMentions постранично отдает ленту упоминаний пользователя
+func (s *Server) ListMentions(ctx context.Context, req *chatdesc.ListMentionsRequest) (*chatdesc.ListMentionsResponse, error) {
+	tokenUser := auth.UserFromContext(ctx)
+	page, err := s.chatService.ListMentions(ctx, models.ListMentions{
+		UserId:     tokenUser.ID,
+		Cursor:     req.GetCursor(),
+		Limit:      uint64(req.GetLimit()),
+		UnreadOnly: req.GetUnreadOnly(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &chatdesc.ListMentionsResponse{
+		Mentions:    toMentionItemsDesc(page.Mentions),
+		NextCursor:  page.NextCursor,
+		UnreadCount: page.Unread,
+	}, nil
+}
@@ -0,0 +1,75 @@
+package grpc_server
+
+import (
+	"context"
+
+	"github.com/rkchv/auth/pkg/user_v1/auth"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/rkchv/chat/internal/services/models"
+	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
+)
+
+// AddMembers добавляет участников в чат
+func (s *Server) AddMembers(ctx context.Context, req *chatdesc.AddMembersRequest) (*chatdesc.AddMembersResponse, error) {
+	tokenUser := auth.UserFromContext(ctx)
+	added, err := s.chatService.AddMembers(ctx, models.AddMembers{
+		ChatId:  req.GetChatId(),
+		UserId:  tokenUser.ID,
+		Members: toMembers(req.GetMembers()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, userId := range added {
+		s.broadcast(ctx, memberJoinedEvent(req.GetChatId(), userId))
+	}
+
+	return &chatdesc.AddMembersResponse{Added: added}, nil
+}
+
+// RemoveMember исключает участника из чата, его подключения к чату закрываются
+func (s *Server) RemoveMember(ctx context.Context, req *chatdesc.RemoveMemberRequest) (*emptypb.Empty, error) {
+	tokenUser := auth.UserFromContext(ctx)
+	err := s.chatService.RemoveMember(ctx, models.RemoveMember{
+		ChatId:   req.GetChatId(),
+		UserId:   tokenUser.ID,
+		MemberId: req.GetUserId(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reason := chatdesc.MemberLeftReason_MEMBER_LEFT_REASON_REMOVED
+	if req.GetUserId() == tokenUser.ID {
+		reason = chatdesc.MemberLeftReason_MEMBER_LEFT_REASON_LEFT
+	}
+	s.broadcast(ctx, memberLeftEvent(req.GetChatId(), req.GetUserId(), reason))
+
+	return &emptypb.Empty{}, nil
+}
+
+// LeaveChat выход из чата
+func (s *Server) LeaveChat(ctx context.Context, req *chatdesc.LeaveChatRequest) (*emptypb.Empty, error) {
+	tokenUser := auth.UserFromContext(ctx)
+	err := s.chatService.LeaveChat(ctx, req.GetChatId(), tokenUser.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.broadcast(ctx, memberLeftEvent(req.GetChatId(), tokenUser.ID, chatdesc.MemberLeftReason_MEMBER_LEFT_REASON_LEFT))
+
+	return &emptypb.Empty{}, nil
+}
+
+// ListMembers участники чата с отображаемыми именами
+func (s *Server) ListMembers(ctx context.Context, req *chatdesc.ListMembersRequest) (*chatdesc.ListMembersResponse, error) {
+	tokenUser := auth.UserFromContext(ctx)
+	members, err := s.chatService.ListMembers(ctx, req.GetChatId(), tokenUser.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &chatdesc.ListMembersResponse{Members: toMembersDesc(members)}, nil
+}
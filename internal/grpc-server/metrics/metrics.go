@@ -10,6 +10,8 @@ const appName = "chat"
 type Metrics struct {
 	openedChats      prometheus.Gauge
 	connectedClients prometheus.Gauge
+	slowSubscribers  *prometheus.CounterVec
+	droppedEvents    prometheus.Counter
 }
 
 func NewMetrics() *Metrics {
@@ -26,6 +28,18 @@ func NewMetrics() *Metrics {
 			Name:      appName + "_connected_clients_cnt",
 			Help:      "Кол-во подключенных клиентов",
 		}),
+		slowSubscribers: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "chat",
+			Subsystem: "stream",
+			Name:      appName + "_slow_subscribers_total",
+			Help:      "Действия с подписчиками, которые не успевают получать события",
+		}, []string{"action"}),
+		droppedEvents: promauto.NewCounter(prometheus.CounterOpts{
+			Namespace: "chat",
+			Subsystem: "stream",
+			Name:      appName + "_dropped_events_total",
+			Help:      "События, отброшенные из-за переполненной очереди чата",
+		}),
 	}
 }
 
@@ -44,3 +58,11 @@ func (m *Metrics) IncreaseClients() {
 func (m *Metrics) DecreaseClients() {
 	m.connectedClients.Dec()
 }
+
+func (m *Metrics) SlowSubscriber(action string) {
+	m.slowSubscribers.WithLabelValues(action).Inc()
+}
+
+func (m *Metrics) DroppedEvent() {
+	m.droppedEvents.Inc()
+}
@@ -0,0 +1,78 @@
+package grpc_server
+
+import (
+	"context"
+	"time"
+
+	"github.com/rkchv/auth/pkg/user_v1/auth"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/rkchv/chat/internal/services/models"
+	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
+)
+
+// BanMember блокирует пользователя в чате. Событие о выходе закрывает его стримы на всех экземплярах
+func (s *Server) BanMember(ctx context.Context, req *chatdesc.BanMemberRequest) (*emptypb.Empty, error) {
+	tokenUser := auth.UserFromContext(ctx)
+	wasMember, err := s.chatService.BanMember(ctx, models.BanMember{
+		ChatId:   req.GetChatId(),
+		UserId:   tokenUser.ID,
+		MemberId: req.GetUserId(),
+		Reason:   req.GetReason(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if wasMember {
+		s.broadcast(ctx, memberLeftEvent(req.GetChatId(), req.GetUserId(), chatdesc.MemberLeftReason_MEMBER_LEFT_REASON_BANNED))
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// UnbanMember снимает блокировку
+func (s *Server) UnbanMember(ctx context.Context, req *chatdesc.MemberRequest) (*emptypb.Empty, error) {
+	tokenUser := auth.UserFromContext(ctx)
+	err := s.chatService.UnbanMember(ctx, req.GetChatId(), tokenUser.ID, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// MuteMember запрещает участнику писать в чат
+func (s *Server) MuteMember(ctx context.Context, req *chatdesc.MuteMemberRequest) (*emptypb.Empty, error) {
+	tokenUser := auth.UserFromContext(ctx)
+	mute := models.MuteMember{
+		ChatId:   req.GetChatId(),
+		UserId:   tokenUser.ID,
+		MemberId: req.GetUserId(),
+	}
+	if req.GetUntil() != nil {
+		mute.Until = req.GetUntil().AsTime()
+	}
+
+	err := s.chatService.MuteMember(ctx, mute)
+	if err != nil {
+		return nil, err
+	}
+
+	s.broadcast(ctx, memberMutedEvent(req.GetChatId(), req.GetUserId(), true, mute.Until))
+
+	return &emptypb.Empty{}, nil
+}
+
+// UnmuteMember снимает запрет писать
+func (s *Server) UnmuteMember(ctx context.Context, req *chatdesc.MemberRequest) (*emptypb.Empty, error) {
+	tokenUser := auth.UserFromContext(ctx)
+	err := s.chatService.UnmuteMember(ctx, req.GetChatId(), tokenUser.ID, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	s.broadcast(ctx, memberMutedEvent(req.GetChatId(), req.GetUserId(), false, time.Time{}))
+
+	return &emptypb.Empty{}, nil
+}
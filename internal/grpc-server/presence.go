@@ -0,0 +1,75 @@
+package grpc_server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rkchv/auth/pkg/user_v1/auth"
+
+	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
+)
+
+// GetPresence в сети ли пользователи и когда их видели последний раз, только для тех, с кем есть общий чат
+func (s *Server) GetPresence(ctx context.Context, req *chatdesc.GetPresenceRequest) (*chatdesc.GetPresenceResponse, error) {
+	tokenUser := auth.UserFromContext(ctx)
+	users, err := s.chatService.GetPresence(ctx, tokenUser.ID, req.GetUserIds())
+	if err != nil {
+		return nil, err
+	}
+
+	return &chatdesc.GetPresenceResponse{Users: toPresenceDesc(users)}, nil
+}
+
+// presenceTracker считает подключения пользователей к этому экземпляру во всех чатах,
+// чтобы снимать отметку присутствия только с последним подключением
+type presenceTracker struct {
+	m     sync.Mutex
+	conns map[int64]int
+}
+
+func newPresenceTracker() *presenceTracker {
+	return &presenceTracker{conns: make(map[int64]int)}
+}
+
+func (t *presenceTracker) connect(userId int64) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	t.conns[userId]++
+}
+
+// disconnect возвращает true, если у пользователя не осталось подключений
+func (t *presenceTracker) disconnect(userId int64) bool {
+	t.m.Lock()
+	defer t.m.Unlock()
+	t.conns[userId]--
+	if t.conns[userId] > 0 {
+		return false
+	}
+	delete(t.conns, userId)
+	return true
+}
+
+// keepOnline отмечает пользователя в сети при подключении и затем обновляет отметку, пока не закроется контекст.
+// С последним подключением пользователь уходит из сети
+func (s *Server) keepOnline(ctx context.Context, userId int64) {
+	s.presence.connect(userId)
+	defer func() {
+		if s.presence.disconnect(userId) {
+			_ = s.chatService.Offline(context.WithoutCancel(ctx), userId)
+		}
+	}()
+
+	_ = s.chatService.Online(ctx, userId)
+
+	t := time.NewTicker(s.session.PresenceTTL / 3)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			_ = s.chatService.Online(ctx, userId)
+		}
+	}
+}
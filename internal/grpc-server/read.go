@@ -0,0 +1,47 @@
+package grpc_server
+
+import (
+	"context"
+
+	"github.com/rkchv/auth/pkg/user_v1/auth"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/rkchv/chat/internal/services/models"
+	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
+)
+
+// MarkRead сохраняет позицию прочтения и рассылает ее участникам чата
+func (s *Server) MarkRead(ctx context.Context, req *chatdesc.MarkReadRequest) (*emptypb.Empty, error) {
+	tokenUser := auth.UserFromContext(ctx)
+	position, err := s.chatService.MarkRead(ctx, models.MarkRead{
+		ChatId:    req.GetChatId(),
+		UserId:    tokenUser.ID,
+		MessageId: req.GetMessageId(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if position != nil {
+		s.broadcast(ctx, readPositionEvent(req.GetChatId(), *position))
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// SeenBy кто прочитал сообщение
+func (s *Server) SeenBy(ctx context.Context, req *chatdesc.SeenByRequest) (*chatdesc.SeenByResponse, error) {
+	tokenUser := auth.UserFromContext(ctx)
+	readers, err := s.chatService.SeenBy(ctx, req.GetChatId(), req.GetMessageId(), tokenUser.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &chatdesc.SeenByResponse{Readers: make([]*chatdesc.Reader, 0, len(readers))}
+	for _, r := range readers {
+		res.Readers = append(res.Readers, &chatdesc.Reader{UserId: r.UserId, UserName: r.UserName, ReadAt: timestamppb.New(r.ReadAt)})
+	}
+
+	return res, nil
+}
@@ -0,0 +1,64 @@
+package grpc_server
+
+import (
+	"context"
+
+	"github.com/rkchv/auth/pkg/user_v1/auth"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/rkchv/chat/internal/services/models"
+	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
+)
+
+// SetMemberRole меняет роль участника чата
+func (s *Server) SetMemberRole(ctx context.Context, req *chatdesc.SetMemberRoleRequest) (*emptypb.Empty, error) {
+	tokenUser := auth.UserFromContext(ctx)
+	err := s.chatService.SetMemberRole(ctx, models.SetMemberRole{
+		ChatId:   req.GetChatId(),
+		UserId:   tokenUser.ID,
+		MemberId: req.GetUserId(),
+		Role:     toRole(req.GetRole()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.broadcast(ctx, memberRoleChangedEvent(req.GetChatId(), req.GetUserId(), req.GetRole()))
+
+	return &emptypb.Empty{}, nil
+}
+
+// TransferOwnership передает владение чатом другому участнику
+func (s *Server) TransferOwnership(ctx context.Context, req *chatdesc.TransferOwnershipRequest) (*emptypb.Empty, error) {
+	tokenUser := auth.UserFromContext(ctx)
+	err := s.chatService.TransferOwnership(ctx, models.TransferOwnership{
+		ChatId:     req.GetChatId(),
+		UserId:     tokenUser.ID,
+		NewOwnerId: req.GetUserId(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.broadcast(ctx, memberRoleChangedEvent(req.GetChatId(), tokenUser.ID, chatdesc.MemberRole_MEMBER_ROLE_ADMIN))
+	s.broadcast(ctx, memberRoleChangedEvent(req.GetChatId(), req.GetUserId(), chatdesc.MemberRole_MEMBER_ROLE_OWNER))
+
+	return &emptypb.Empty{}, nil
+}
+
+// PinMessage закрепляет сообщение в чате или снимает закрепление
+func (s *Server) PinMessage(ctx context.Context, req *chatdesc.PinMessageRequest) (*emptypb.Empty, error) {
+	tokenUser := auth.UserFromContext(ctx)
+	err := s.chatService.PinMessage(ctx, models.PinMessage{
+		ChatId:    req.GetChatId(),
+		UserId:    tokenUser.ID,
+		MessageId: req.GetMessageId(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.broadcast(ctx, messagePinnedEvent(req.GetChatId(), req.GetMessageId()))
+
+	return &emptypb.Empty{}, nil
+}
@@ -2,31 +2,42 @@ package grpc_server
 
 import (
 	"context"
-	"time"
 
 	"github.com/rkchv/auth/pkg/user_v1/auth"
-	syserr "github.com/rkchv/chat/lib/error"
+	"github.com/rkchv/chat/lib/logger"
+	"golang.org/x/exp/slog"
 	"google.golang.org/protobuf/types/known/emptypb"
-	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/rkchv/chat/internal/services/models"
 	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
 )
 
-// SendMessage отправляет сообщение в чат
+// SendMessage сохраняет сообщение и отправляет его в чат
 func (s *Server) SendMessage(ctx context.Context, req *chatdesc.SendMessageRequest) (*emptypb.Empty, error) {
-	s.m.RLock()
-	existChat, ok := s.connectedChats[req.GetChatId()]
-	s.m.RUnlock()
+	tokenUser := auth.UserFromContext(ctx)
+	msg, mentioned, err := s.chatService.SendMessage(ctx, models.SendMessage{
+		ChatId:        req.GetChatId(),
+		UserId:        tokenUser.ID,
+		Text:          req.GetText(),
+		AttachmentIds: req.GetAttachmentIds(),
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	if ok {
-		tokenUser := auth.UserFromContext(ctx)
-		existChat.AddMessage(&chatdesc.Message{
-			From:      tokenUser.ID,
-			Text:      req.GetText(),
-			Timestamp: timestamppb.New(time.Now()),
-		})
-		return &emptypb.Empty{}, nil
+	s.broadcast(ctx, messageEvent(msg))
+	if len(mentioned) > 0 {
+		s.broadcast(ctx, mentionedEvent(msg, mentioned))
 	}
 
-	return nil, syserr.New("Чат не найден", syserr.NotFound)
+	return &emptypb.Empty{}, nil
+}
+
+// broadcast публикует событие для всех экземпляров сервиса, каждый разошлет его своим подключенным клиентам.
+// Событие уже сохранено (или не требует сохранения), поэтому ошибку публикации только логируем
+func (s *Server) broadcast(ctx context.Context, event *chatdesc.ChatEvent) {
+	err := s.broker.Publish(ctx, event)
+	if err != nil {
+		logger.GetLogger(ctx).Error("failed to publish chat event", slog.String("error", err.Error()), slog.Int64("chatId", event.GetChatId()))
+	}
 }
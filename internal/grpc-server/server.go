@@ -1,11 +1,16 @@
 package grpc_server
 
 import (
+	"context"
 	"sync"
 	"time"
 
+	"golang.org/x/exp/slog"
+
+	"github.com/rkchv/chat/internal/config"
 	"github.com/rkchv/chat/internal/grpc-server/metrics"
 	"github.com/rkchv/chat/internal/grpc-server/streaming"
+	"github.com/rkchv/chat/internal/pubsub"
 	"github.com/rkchv/chat/internal/services"
 	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
 )
@@ -13,51 +18,91 @@ import (
 type Server struct {
 	chatdesc.UnimplementedChatV1Server
 	chatService    *services.Service
+	broker         pubsub.Broker
 	connectedChats map[int64]streaming.Chat
 	m              sync.RWMutex
 	metrics        *metrics.Metrics
-	garbageCycle   time.Duration
 	chatExpiration time.Duration
+	historyLimit   uint64
+	session        config.Session
+	presence       *presenceTracker
+	queue          streaming.Queue
+	log            *slog.Logger
 }
 
-func NewServer(srv *services.Service, garbageCycle time.Duration, chatExpired time.Duration) *Server {
-	return &Server{
+func NewServer(srv *services.Service, broker pubsub.Broker, chatExpired time.Duration, historyLimit uint64, session config.Session, queue streaming.Queue, log *slog.Logger) *Server {
+	s := &Server{
 		chatService:    srv,
+		broker:         broker,
 		metrics:        metrics.NewMetrics(),
 		connectedChats: make(map[int64]streaming.Chat),
-		garbageCycle:   garbageCycle,
 		chatExpiration: chatExpired,
+		historyLimit:   historyLimit,
+		session:        session,
+		presence:       newPresenceTracker(),
+		queue:          queue,
+		log:            log,
+	}
+	s.queue.OnSlow = func(action streaming.SlowAction) {
+		s.metrics.SlowSubscriber(string(action))
 	}
+
+	return s
 }
 
-func (s *Server) NewChat(chatId int64) streaming.Chat {
+// OpenChat возвращает открытый в этом экземпляре чат, если его нет - подписывается на его события у брокера,
+// открывает и запускает рассылку
+func (s *Server) OpenChat(ctx context.Context, chatId int64) (streaming.Chat, error) {
 	s.m.Lock()
 	defer s.m.Unlock()
 
-	if _, ok := s.connectedChats[chatId]; ok {
-		return streaming.NewChat(chatId)
+	if existChat, ok := s.connectedChats[chatId]; ok {
+		return existChat, nil
+	}
+
+	if err := s.broker.Subscribe(ctx, chatId); err != nil {
+		return nil, err
 	}
 
-	newChat := streaming.NewChat(chatId)
+	newChat := streaming.NewChat(chatId, s.session.TypingTTL, s.chatExpiration, s.queue, s.CloseChat)
 	s.connectedChats[chatId] = newChat
 	s.metrics.IncreaseChats()
 
-	return newChat
+	go newChat.Run()
+
+	return newChat, nil
+}
+
+// Dispatch передает пришедшее от брокера событие в открытый в этом экземпляре чат.
+// Если чат здесь не открыт, значит подписчиков на него здесь нет и событие некому отдавать
+func (s *Server) Dispatch(event *chatdesc.ChatEvent) {
+	s.m.RLock()
+	existChat, ok := s.connectedChats[event.GetChatId()]
+	s.m.RUnlock()
+	if !ok {
+		return
+	}
+
+	// рассылка этого чата не успевает, а ждать ее нельзя: обработчик брокера общий для всех чатов
+	if !existChat.AddEvent(event) {
+		s.metrics.DroppedEvent()
+		s.log.Warn("chat events buffer is full, event dropped", slog.Int64("chatId", event.GetChatId()))
+	}
 }
 
 func (s *Server) CloseChat(ch streaming.Chat) {
-	ch.Close()
 	s.m.Lock()
 	defer s.m.Unlock()
+	if existChat, ok := s.connectedChats[ch.ID()]; !ok || existChat != ch {
+		return
+	}
+
+	ch.Close()
 	delete(s.connectedChats, ch.ID())
 	s.metrics.DecreaseChats()
-}
 
-func (s *Server) CloseChatByID(chatId int64) {
-	s.m.RLock()
-	ch, ok := s.connectedChats[chatId]
-	s.m.RUnlock()
-	if ok {
-		s.CloseChat(ch)
+	// подписчиков здесь больше нет, события чата этому экземпляру не нужны
+	if err := s.broker.Unsubscribe(context.Background(), ch.ID()); err != nil {
+		s.log.Error("failed to unsubscribe from chat events", slog.String("error", err.Error()), slog.Int64("chatId", ch.ID()))
 	}
 }
@@ -0,0 +1,87 @@
+package grpc_server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/rkchv/auth/pkg/user_v1/auth"
+	syserr "github.com/rkchv/chat/lib/error"
+
+	"github.com/rkchv/chat/internal/services/models"
+	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
+)
+
+// Session двунаправленная сессия участника чата. Первое сообщение клиента открывает подписку на события чата,
+// дальше клиент шлет набор текста и heartbeat. Набор текста и присутствие только рассылаются и нигде не хранятся
+func (s *Server) Session(stream chatdesc.ChatV1_SessionServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	open := req.GetOpen()
+	if open == nil {
+		return syserr.New("Сессия должна начинаться с open", syserr.InvalidArgument)
+	}
+
+	ctx, cancel := context.WithCancelCause(stream.Context())
+	defer cancel(nil)
+
+	// сообщения клиента читаются только после подключения к чату: до проверки прав набор текста и heartbeat
+	// постороннего не должны ни рассылаться, ни отмечать его в сети
+	err = s.subscribe(open, &sessionStream{ChatV1_SessionServer: stream, ctx: ctx}, func() {
+		go s.readSession(ctx, cancel, open.GetChatId(), stream)
+	})
+	// клиент закрыл свою сторону стрима - штатное завершение сессии
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	return err
+}
+
+// readSession читает сообщения клиента, пока сессия открыта. Ошибка чтения или долгое молчание клиента закрывают сессию
+func (s *Server) readSession(ctx context.Context, cancel context.CancelCauseFunc, chatId int64, stream chatdesc.ChatV1_SessionServer) {
+	userId := auth.UserFromContext(ctx).ID
+
+	idle := time.AfterFunc(s.session.IdleTimeout, func() {
+		cancel(syserr.New("Сессия закрыта: клиент не присылал heartbeat", syserr.DeadlineExceeded))
+	})
+	defer idle.Stop()
+
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			cancel(err)
+			return
+		}
+		idle.Reset(s.session.IdleTimeout)
+
+		switch {
+		case req.GetTyping() != nil:
+			// набор от того, кто не может писать в чат, просто не рассылается
+			err = s.chatService.Typing(ctx, models.Connect{ChatId: chatId, UserId: userId})
+			if err != nil {
+				continue
+			}
+			s.broadcast(ctx, typingEvent(chatId, userId, req.GetTyping().GetTyping()))
+		case req.GetHeartbeat() != nil:
+			_ = s.chatService.Online(ctx, userId)
+		default:
+			cancel(syserr.New("Сессия уже открыта", syserr.InvalidArgument))
+			return
+		}
+	}
+}
+
+// sessionStream стрим сессии с контекстом, который закрывается и при завершении чтения сообщений клиента
+type sessionStream struct {
+	chatdesc.ChatV1_SessionServer
+	ctx context.Context
+}
+
+func (s *sessionStream) Context() context.Context {
+	return s.ctx
+}
@@ -1,54 +1,194 @@
 package streaming
 
 import (
+	"context"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
 )
 
+// Stream стрим подписчика, в который чат рассылает события
+type Stream interface {
+	Send(event *chatdesc.ChatEvent) error
+	Context() context.Context
+}
+
+// ConnectionID идентификатор одного подключения (устройства) пользователя к чату
+type ConnectionID struct {
+	UserID int64
+	ConnID uint64
+}
+
+// eventsBufferSize сколько событий чат принимает впрок, пока Run рассылает предыдущие. События приходят из общего
+// для всех чатов обработчика брокера, который ждать не должен
+const eventsBufferSize = 1024
+
+// connSeq счетчик подключений, дает уникальный в рамках процесса номер каждому стриму
+var connSeq atomic.Uint64
+
 type Chat interface {
 	ID() int64
-	Connect(userID int64, stream chatdesc.ChatV1_ConnectServer)
-	Disconnect(userID int64)
+	// Connect подключает стрим, в возвращаемый канал приходит причина, если чат сам отключает этот стрим
+	Connect(userID int64, stream Stream) (ConnectionID, <-chan error)
+	// Disconnect отключает стрим, перед возвратом ненадолго дает дослать его очередь
+	Disconnect(id ConnectionID)
+	// Online есть ли у пользователя хотя бы одно подключение к чату
+	Online(userID int64) bool
 	IsEmpty() bool
 	Close()
-	AddMessage(msg *chatdesc.Message)
-	BroadcastMessages()
+	// Done закрывается, когда чат закрыт
+	Done() <-chan struct{}
+	// AddEvent передает событие на рассылку не блокируясь, false - очередь чата переполнена и событие отброшено
+	AddEvent(event *chatdesc.ChatEvent) bool
+	// Run рассылает события подписчикам, пока чат не закрыт. Без событий ждет, не нагружая процессор
+	Run()
 }
 
 // chat Чат
 type chat struct {
 	id          int64
-	messages    chan *chatdesc.Message
-	connections map[int64]chatdesc.ChatV1_ConnectServer
+	events      chan *chatdesc.ChatEvent
+	connections map[ConnectionID]*connection
 	m           sync.RWMutex
+	done        chan struct{}
+	closeOnce   sync.Once
+	// typing таймеры истечения набора текста по пользователям, состояние живет только в памяти
+	typing    map[int64]*time.Timer
+	typingM   sync.Mutex
+	typingTTL time.Duration
+	// idle таймер простоя, заведен, пока к чату никто не подключен. Меняется под m
+	idle        *time.Timer
+	idleTimeout time.Duration
+	// release вызывается, когда чат пора закрыть: он простаивал idleTimeout или разослал событие о закрытии
+	release func(Chat)
+	queue   Queue
 }
 
-// NewChat Создает новый чат
-func NewChat(id int64) Chat {
-	return &chat{
+// NewChat Создает новый чат. Набор текста, который не повторили за typingTTL, считается законченным.
+// Если к чату никто не подключен дольше idleTimeout, вызывается release, который должен закрыть чат.
+// Каждому подписчику события отправляются через свою очередь, queue задает ее размер и что делать при переполнении
+func NewChat(id int64, typingTTL time.Duration, idleTimeout time.Duration, queue Queue, release func(Chat)) Chat {
+	queue.Size = max(queue.Size, 1)
+
+	c := &chat{
 		id:          id,
-		connections: make(map[int64]chatdesc.ChatV1_ConnectServer),
-		messages:    make(chan *chatdesc.Message),
+		connections: make(map[ConnectionID]*connection),
+		events:      make(chan *chatdesc.ChatEvent, eventsBufferSize),
+		done:        make(chan struct{}),
+		typing:      make(map[int64]*time.Timer),
+		typingTTL:   typingTTL,
+		idleTimeout: idleTimeout,
+		release:     release,
+		queue:       queue,
 	}
+
+	// чат открывается под первое подключение, но оно может и не состояться
+	c.m.Lock()
+	c.startIdle()
+	c.m.Unlock()
+
+	return c
 }
 
 func (c *chat) ID() int64 {
 	return c.id
 }
 
-// Connect Подключает пользователя (его стрим) к чату
-func (c *chat) Connect(userID int64, stream chatdesc.ChatV1_ConnectServer) {
+// Connect Подключает стрим пользователя к чату. У одного пользователя может быть несколько подключений с разных устройств
+func (c *chat) Connect(userID int64, stream Stream) (ConnectionID, <-chan error) {
+	id := ConnectionID{UserID: userID, ConnID: connSeq.Add(1)}
+	conn := newConnection(stream, c.queue.Size)
+
 	c.m.Lock()
 	defer c.m.Unlock()
-	c.connections[userID] = stream
+	c.connections[id] = conn
+	c.stopIdle()
+
+	return id, conn.kicked
 }
 
-// Disconnect Отключает пользователя (его стрим) от чата
-func (c *chat) Disconnect(userID int64) {
+// Disconnect Отключает от чата только заданный стрим, остальные подключения пользователя остаются
+func (c *chat) Disconnect(id ConnectionID) {
 	c.m.Lock()
-	defer c.m.Unlock()
-	delete(c.connections, userID)
+	conn, ok := c.connections[id]
+	delete(c.connections, id)
+	c.startIdle()
+	c.m.Unlock()
+
+	if !ok {
+		return
+	}
+	conn.close(nil)
+	conn.wait(flushTimeout)
+}
+
+// kick Останавливает рассылку в стрим и передает подписчику причину. Из подключений стрим убирает сам подписчик
+// через Disconnect, чтобы дождаться отправки уже поставленных в очередь событий
+func (c *chat) kick(id ConnectionID, reason error) {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	if conn, ok := c.connections[id]; ok {
+		conn.close(reason)
+	}
+}
+
+// disconnectUser Отключает от чата все стримы пользователя
+func (c *chat) disconnectUser(userID int64) {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	for id, conn := range c.connections {
+		if id.UserID == userID {
+			conn.close(ErrRemoved)
+		}
+	}
+}
+
+// startIdle заводит таймер простоя, если подключений не осталось. Вызывается под m
+func (c *chat) startIdle() {
+	if len(c.connections) > 0 || c.idle != nil || c.closed() {
+		return
+	}
+
+	var t *time.Timer
+	t = time.AfterFunc(c.idleTimeout, func() {
+		c.m.Lock()
+		// за время ожидания кто-то подключился или таймер уже перезаведен
+		expired := c.idle == t && len(c.connections) == 0
+		if expired {
+			c.idle = nil
+		}
+		c.m.Unlock()
+
+		if expired {
+			c.release(c)
+		}
+	})
+	c.idle = t
+}
+
+// stopIdle останавливает таймер простоя при подключении. Вызывается под m
+func (c *chat) stopIdle() {
+	if c.idle != nil {
+		c.idle.Stop()
+		c.idle = nil
+	}
+}
+
+func (c *chat) Online(userID int64) bool {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	for id := range c.connections {
+		if id.UserID == userID {
+			return true
+		}
+	}
+
+	return false
 }
 
 // IsEmpty Проверяет есть ли в чате еще активные соединения (стримы)
@@ -60,30 +200,181 @@ func (c *chat) IsEmpty() bool {
 
 // Close Закрывает чат и освобождает ресурсы
 func (c *chat) Close() {
-	close(c.messages)
-	c.m.Lock()
-	defer c.m.Unlock()
-	c.connections = nil
+	c.closeOnce.Do(func() {
+		close(c.done)
+
+		c.typingM.Lock()
+		for _, t := range c.typing {
+			t.Stop()
+		}
+		clear(c.typing)
+		c.typingM.Unlock()
+
+		// подписчики увидят закрытие чата и сами отключатся, дослав очереди
+		c.m.Lock()
+		defer c.m.Unlock()
+		c.stopIdle()
+		for _, conn := range c.connections {
+			conn.close(nil)
+		}
+	})
+}
+
+func (c *chat) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *chat) closed() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
 }
 
-func (c *chat) AddMessage(msg *chatdesc.Message) {
-	c.messages <- msg
+// AddEvent Передает событие на рассылку, в закрытый чат события не принимаются
+func (c *chat) AddEvent(event *chatdesc.ChatEvent) bool {
+	if c.closed() {
+		return true
+	}
+
+	select {
+	case c.events <- event:
+		return true
+	default:
+		return false
+	}
 }
 
-func (c *chat) BroadcastMessages() {
+func (c *chat) Run() {
 	for {
 		select {
-		case msg, ok := <-c.messages:
-			if !ok {
-				return
+		case event := <-c.events:
+			c.send(event)
+			c.trackTyping(event)
+
+			// вышедший из чата участник получает событие о выходе последним
+			if left := event.GetMemberLeft(); left != nil {
+				c.disconnectUser(left.GetUserId())
 			}
-			c.m.RLock()
-			for _, stream := range c.connections {
-				_ = stream.Send(msg)
+
+			// закрываем только после рассылки, чтобы подписчики успели получить событие о закрытии
+			if event.GetChatClosed() != nil {
+				c.release(c)
+				return
 			}
-			c.m.RUnlock()
-		default:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// send ставит событие в очереди подключенных стримов. Событие об упоминании получают только упомянутые.
+// Постановка в очередь не ждет подписчика ни при какой политике, так что медленный подписчик не задерживает
+// рассылку остальным
+func (c *chat) send(event *chatdesc.ChatEvent) {
+	var recipients []int64
+	if mentioned := event.GetMentioned(); mentioned != nil {
+		recipients = mentioned.GetUserIds()
+	}
+
+	type target struct {
+		id   ConnectionID
+		conn *connection
+	}
+
+	c.m.RLock()
+	targets := make([]target, 0, len(c.connections))
+	for id, conn := range c.connections {
+		if (recipients != nil && !slices.Contains(recipients, id.UserID)) || conn.stopped() {
+			continue
+		}
+		targets = append(targets, target{id: id, conn: conn})
+	}
+	c.m.RUnlock()
+
+	for _, t := range targets {
+		if c.enqueue(t.conn, event) {
+			c.kick(t.id, ErrSlowConsumer)
+			c.observe(SlowDisconnected)
+		}
+	}
+}
+
+// trackTyping ведет состояние набора текста. Начало набора заводит таймер, по истечении которого всем рассылается
+// конец набора. Новое сообщение, уход из чата или из сети заканчивают набор сразу.
+// Каждый экземпляр получает те же события, поэтому набор истекает у всех одинаково без общего хранилища
+func (c *chat) trackTyping(event *chatdesc.ChatEvent) {
+	switch {
+	case event.GetTyping() != nil:
+		typing := event.GetTyping()
+		if typing.GetTyping() {
+			c.startTyping(typing.GetUserId(), event)
+		} else {
+			c.stopTyping(typing.GetUserId())
+		}
+	case event.GetMessageCreated() != nil:
+		c.endTyping(event, event.GetMessageCreated().GetFrom())
+	case event.GetMemberLeft() != nil:
+		c.endTyping(event, event.GetMemberLeft().GetUserId())
+	case event.GetPresence() != nil && !event.GetPresence().GetOnline():
+		c.endTyping(event, event.GetPresence().GetUserId())
+	}
+}
+
+func (c *chat) startTyping(userID int64, event *chatdesc.ChatEvent) {
+	c.typingM.Lock()
+	defer c.typingM.Unlock()
+
+	if t, ok := c.typing[userID]; ok {
+		t.Stop()
+	}
+
+	var t *time.Timer
+	t = time.AfterFunc(c.typingTTL, func() {
+		c.typingM.Lock()
+		current, ok := c.typing[userID]
+		if !ok || current != t {
+			c.typingM.Unlock()
 			return
 		}
+		delete(c.typing, userID)
+		c.typingM.Unlock()
+
+		_ = c.AddEvent(typingStopped(event, userID))
+	})
+	c.typing[userID] = t
+}
+
+// stopTyping убирает таймер набора, возвращает, набирал ли пользователь текст
+func (c *chat) stopTyping(userID int64) bool {
+	c.typingM.Lock()
+	defer c.typingM.Unlock()
+
+	t, ok := c.typing[userID]
+	if !ok {
+		return false
+	}
+	t.Stop()
+	delete(c.typing, userID)
+
+	return true
+}
+
+// endTyping заканчивает набор из-за другого события и сразу рассылает конец набора
+func (c *chat) endTyping(cause *chatdesc.ChatEvent, userID int64) {
+	if c.stopTyping(userID) {
+		c.send(typingStopped(cause, userID))
+	}
+}
+
+// typingStopped событие о конце набора на основе другого события чата, чтобы сохранить версию формата
+func typingStopped(base *chatdesc.ChatEvent, userID int64) *chatdesc.ChatEvent {
+	return &chatdesc.ChatEvent{
+		Version:   base.GetVersion(),
+		ChatId:    base.GetChatId(),
+		Timestamp: timestamppb.Now(),
+		Event:     &chatdesc.ChatEvent_Typing{Typing: &chatdesc.Typing{UserId: userID, Typing: false}},
 	}
 }
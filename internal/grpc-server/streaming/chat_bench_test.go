@@ -0,0 +1,85 @@
+//go:build linux
+
+package streaming
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
+)
+
+// countingStream стрим, который только считает отправленные события
+type countingStream struct {
+	sent *atomic.Int64
+}
+
+func (s countingStream) Send(_ *chatdesc.ChatEvent) error {
+	s.sent.Add(1)
+	return nil
+}
+
+func (s countingStream) Context() context.Context {
+	return context.Background()
+}
+
+// cpuTime процессорное время процесса
+func cpuTime(b *testing.B) time.Duration {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		b.Fatal(err)
+	}
+
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano())
+}
+
+// BenchmarkIdleChats процессорное время открытых чатов без событий на миллисекунду ожидания.
+// Чаты ждут событий, не крутясь в цикле, поэтому cpu-ns/op должно быть близко к нулю
+func BenchmarkIdleChats(b *testing.B) {
+	const chats = 50
+
+	var sent atomic.Int64
+	for i := 0; i < chats; i++ {
+		c := NewChat(int64(i), time.Minute, time.Hour, Queue{Size: 16, Policy: DropOldest}, func(Chat) {})
+		defer c.Close()
+		c.Connect(1, countingStream{sent: &sent})
+		go c.Run()
+	}
+
+	b.ResetTimer()
+	start := cpuTime(b)
+	for i := 0; i < b.N; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	b.ReportMetric(float64(cpuTime(b)-start)/float64(b.N), "cpu-ns/op")
+}
+
+// BenchmarkChatBroadcast рассылка одного события всем подписчикам чата
+func BenchmarkChatBroadcast(b *testing.B) {
+	const subscribers = 100
+
+	c := NewChat(1, time.Minute, time.Hour, Queue{Size: 1024, Policy: BlockSlow, BlockTimeout: time.Minute}, func(Chat) {})
+	defer c.Close()
+
+	var sent atomic.Int64
+	for i := 0; i < subscribers; i++ {
+		c.Connect(int64(i), countingStream{sent: &sent})
+	}
+	go c.Run()
+
+	e := &chatdesc.ChatEvent{ChatId: 1, Event: &chatdesc.ChatEvent_MessageCreated{MessageCreated: &chatdesc.Message{Id: 1}}}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for !c.AddEvent(e) {
+			runtime.Gosched()
+		}
+	}
+	for sent.Load() < int64(b.N*subscribers) {
+		runtime.Gosched()
+	}
+}
@@ -0,0 +1,43 @@
+package streaming
+
+import (
+	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
+)
+
+// MessageStream адаптер для старых клиентов Connect, которые получают только сообщения.
+// События о сообщениях переводятся в Message с типом события, остальные события пропускаются
+type MessageStream struct {
+	chatdesc.ChatV1_ConnectServer
+}
+
+// NewMessageStream Создает адаптер над стримом Connect
+func NewMessageStream(stream chatdesc.ChatV1_ConnectServer) *MessageStream {
+	return &MessageStream{ChatV1_ConnectServer: stream}
+}
+
+func (s *MessageStream) Send(event *chatdesc.ChatEvent) error {
+	msg := EventToMessage(event)
+	if msg == nil {
+		return nil
+	}
+
+	return s.ChatV1_ConnectServer.Send(msg)
+}
+
+// EventToMessage переводит событие в формат старого стрима, nil если событие не про сообщение
+func EventToMessage(event *chatdesc.ChatEvent) *chatdesc.Message {
+	switch e := event.GetEvent().(type) {
+	case *chatdesc.ChatEvent_MessageCreated:
+		return e.MessageCreated
+	case *chatdesc.ChatEvent_MessageEdited:
+		return e.MessageEdited
+	case *chatdesc.ChatEvent_MessageDeleted:
+		return &chatdesc.Message{
+			Id:        e.MessageDeleted.GetMessageId(),
+			Type:      chatdesc.EventType_EVENT_TYPE_DELETED,
+			Timestamp: event.GetTimestamp(),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,283 @@
+package streaming
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
+)
+
+// SlowPolicy что делать с подписчиком, очередь которого заполнена
+type SlowPolicy string
+
+const (
+	// DropOldest выбросить самое старое событие из очереди и поставить новое
+	DropOldest SlowPolicy = "drop_oldest"
+	// DisconnectSlow сразу отключить подписчика
+	DisconnectSlow SlowPolicy = "disconnect"
+	// BlockSlow ждать места в очереди не дольше BlockTimeout, потом отключить подписчика. Ждет только сам
+	// подписчик, рассылка остальным не задерживается
+	BlockSlow SlowPolicy = "block"
+)
+
+// ParseSlowPolicy проверяет название политики из конфига
+func ParseSlowPolicy(name string) (SlowPolicy, error) {
+	switch policy := SlowPolicy(name); policy {
+	case DropOldest, DisconnectSlow, BlockSlow:
+		return policy, nil
+	}
+
+	return "", fmt.Errorf("unknown slow subscriber policy: %s", name)
+}
+
+// SlowAction действие с медленным подписчиком, учитывается в метриках
+type SlowAction string
+
+const (
+	// SlowDropped из очереди выброшено событие
+	SlowDropped SlowAction = "dropped"
+	// SlowBlocked событиям подписчика пришлось ждать места в очереди
+	SlowBlocked SlowAction = "blocked"
+	// SlowTimedOut место в очереди так и не освободилось
+	SlowTimedOut SlowAction = "timed_out"
+	// SlowDisconnected подписчик отключен
+	SlowDisconnected SlowAction = "disconnected"
+)
+
+// Queue настройки очередей подписчиков
+type Queue struct {
+	Size         int
+	Policy       SlowPolicy
+	BlockTimeout time.Duration
+	// OnSlow вызывается на каждое действие с медленным подписчиком, может быть nil
+	OnSlow func(action SlowAction)
+}
+
+var (
+	// ErrRemoved подписчик отключен, потому что больше не участник чата
+	ErrRemoved = errors.New("subscriber is no longer a chat member")
+	// ErrSlowConsumer подписчик отключен, потому что не успевает получать события
+	ErrSlowConsumer = errors.New("subscriber is too slow")
+)
+
+// flushTimeout сколько при отключении ждать, пока писатель дошлет очередь. Зависший клиент может не принять ничего
+const flushTimeout = time.Second
+
+// connection подключенный к чату стрим со своей очередью событий. События отправляет отдельный писатель,
+// поэтому медленный клиент не задерживает рассылку остальным
+type connection struct {
+	stream Stream
+	queue  chan *chatdesc.ChatEvent
+	// kicked получает причину, когда чат сам отключает стрим
+	kicked chan error
+	// stop закрывается, когда писателю пора заканчивать, finished - когда он закончил
+	stop     chan struct{}
+	stopOnce sync.Once
+	finished chan struct{}
+	// pending события, которые при политике BlockSlow ждут места в очереди. Пока они есть, новые события
+	// встают за ними, чтобы не нарушить порядок. blocked таймер ожидания. Оба меняются под pendingM
+	pending  []*chatdesc.ChatEvent
+	blocked  *time.Timer
+	pendingM sync.Mutex
+	// moved когда писатель последний раз забрал событие, в наносекундах
+	moved atomic.Int64
+}
+
+// newConnection создает подключение и запускает его писателя
+func newConnection(stream Stream, size int) *connection {
+	conn := &connection{
+		stream:   stream,
+		queue:    make(chan *chatdesc.ChatEvent, size),
+		kicked:   make(chan error, 1),
+		stop:     make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+	go conn.write()
+
+	return conn
+}
+
+// write отправляет события из очереди, пока подключение не остановлено. При остановке уже стоящие в очереди события
+// досылаются, чтобы клиент получил, например, событие о своем исключении или о закрытии чата
+func (conn *connection) write() {
+	defer close(conn.finished)
+
+	for {
+		event, ok := conn.next()
+		if !ok {
+			select {
+			case event = <-conn.queue:
+			case <-conn.stop:
+				for event, ok := conn.next(); ok; event, ok = conn.next() {
+					if err := conn.stream.Send(event); err != nil {
+						return
+					}
+				}
+				return
+			}
+		}
+
+		if err := conn.stream.Send(event); err != nil {
+			return
+		}
+	}
+}
+
+// next забирает событие не блокируясь: сначала из очереди, потом из ожидающих места в ней
+func (conn *connection) next() (*chatdesc.ChatEvent, bool) {
+	select {
+	case event := <-conn.queue:
+		conn.moved.Store(time.Now().UnixNano())
+		return event, true
+	default:
+	}
+
+	conn.pendingM.Lock()
+	defer conn.pendingM.Unlock()
+	if len(conn.pending) == 0 {
+		return nil, false
+	}
+
+	event := conn.pending[0]
+	conn.pending[0] = nil
+	conn.pending = conn.pending[1:]
+	conn.moved.Store(time.Now().UnixNano())
+	if len(conn.pending) == 0 && conn.blocked != nil {
+		conn.blocked.Stop()
+		conn.blocked = nil
+	}
+
+	return event, true
+}
+
+// close останавливает писателя. Если задана причина, она передается подписчику через kicked
+func (conn *connection) close(reason error) {
+	conn.stopOnce.Do(func() {
+		if reason != nil {
+			conn.kicked <- reason
+		}
+		close(conn.stop)
+	})
+}
+
+func (conn *connection) stopped() bool {
+	select {
+	case <-conn.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// wait ждет, пока писатель дошлет очередь, но не дольше timeout
+func (conn *connection) wait(timeout time.Duration) {
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+
+	select {
+	case <-conn.finished:
+	case <-t.C:
+	}
+}
+
+// enqueue ставит событие в очередь подписчика по политике для медленных, возвращает, надо ли его отключить
+func (c *chat) enqueue(conn *connection, event *chatdesc.ChatEvent) bool {
+	if c.queue.Policy == BlockSlow {
+		return c.block(conn, event)
+	}
+
+	select {
+	case conn.queue <- event:
+		return false
+	case <-conn.stop:
+		return false
+	default:
+	}
+
+	switch c.queue.Policy {
+	case DropOldest:
+		// писатель мог успеть разобрать очередь сам, тогда выбрасывать нечего
+		select {
+		case <-conn.queue:
+			c.observe(SlowDropped)
+		default:
+		}
+		// новое событие ставит только Run, так что место теперь точно есть
+		conn.queue <- event
+
+		return false
+	default:
+		return true
+	}
+}
+
+// block ставит событие по политике BlockSlow. Run не ждет места в очереди: событие откладывается в pending
+// подключения, а отключает подписчика таймер, если писатель не забирал события дольше BlockTimeout.
+// Отложить можно не больше событий, чем помещается в очередь, дальше подписчик отключается сразу
+func (c *chat) block(conn *connection, event *chatdesc.ChatEvent) bool {
+	conn.pendingM.Lock()
+	defer conn.pendingM.Unlock()
+
+	if len(conn.pending) == 0 {
+		select {
+		case conn.queue <- event:
+			return false
+		case <-conn.stop:
+			return false
+		default:
+		}
+	}
+	if conn.stopped() {
+		return false
+	}
+	if len(conn.pending) >= cap(conn.queue) {
+		return true
+	}
+
+	conn.pending = append(conn.pending, event)
+	if conn.blocked == nil {
+		c.observe(SlowBlocked)
+		conn.moved.Store(time.Now().UnixNano())
+		conn.blocked = c.blockTimer(conn)
+	}
+
+	return false
+}
+
+// blockTimer заводит таймер ожидания места в очереди. Пока писатель забирает события, таймер продлевается
+func (c *chat) blockTimer(conn *connection) *time.Timer {
+	var t *time.Timer
+	t = time.AfterFunc(c.queue.BlockTimeout, func() {
+		conn.pendingM.Lock()
+		// очередь успела освободиться или таймер уже перезаведен
+		if conn.blocked != t {
+			conn.pendingM.Unlock()
+			return
+		}
+		if remaining := c.queue.BlockTimeout - time.Since(time.Unix(0, conn.moved.Load())); remaining > 0 {
+			t.Reset(remaining)
+			conn.pendingM.Unlock()
+			return
+		}
+		conn.blocked = nil
+		conn.pendingM.Unlock()
+
+		if conn.stopped() {
+			return
+		}
+		c.observe(SlowTimedOut)
+		conn.close(ErrSlowConsumer)
+		c.observe(SlowDisconnected)
+	})
+
+	return t
+}
+
+func (c *chat) observe(action SlowAction) {
+	if c.queue.OnSlow != nil {
+		c.queue.OnSlow(action)
+	}
+}
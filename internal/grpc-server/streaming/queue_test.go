@@ -0,0 +1,291 @@
+package streaming
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
+)
+
+// fakeStream стрим подписчика в памяти. Пока block не закрыт, Send ждет, как клиент, который не читает
+type fakeStream struct {
+	block  chan struct{}
+	events chan *chatdesc.ChatEvent
+}
+
+func newFakeStream(buffer int, blocked bool) *fakeStream {
+	s := &fakeStream{block: make(chan struct{}), events: make(chan *chatdesc.ChatEvent, buffer)}
+	if !blocked {
+		close(s.block)
+	}
+
+	return s
+}
+
+func (s *fakeStream) Send(event *chatdesc.ChatEvent) error {
+	<-s.block
+	s.events <- event
+	return nil
+}
+
+func (s *fakeStream) Context() context.Context {
+	return context.Background()
+}
+
+// slowLog собирает действия с медленными подписчиками
+type slowLog struct {
+	m       sync.Mutex
+	actions []SlowAction
+}
+
+func (l *slowLog) observe(action SlowAction) {
+	l.m.Lock()
+	defer l.m.Unlock()
+	l.actions = append(l.actions, action)
+}
+
+func (l *slowLog) get() []SlowAction {
+	l.m.Lock()
+	defer l.m.Unlock()
+	return l.actions
+}
+
+func event(id int64) *chatdesc.ChatEvent {
+	return &chatdesc.ChatEvent{
+		ChatId: 1,
+		Event:  &chatdesc.ChatEvent_MessageCreated{MessageCreated: &chatdesc.Message{Id: id}},
+	}
+}
+
+func TestEnqueue(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     SlowPolicy
+		full       bool
+		stopped    bool
+		disconnect bool
+		queued     int64
+		actions    []SlowAction
+	}{
+		{name: "есть место", policy: DisconnectSlow, queued: 2},
+		{name: "подключение уже остановлено", policy: DisconnectSlow, full: true, stopped: true, queued: 1},
+		{name: "drop_oldest выбрасывает старое", policy: DropOldest, full: true, queued: 2, actions: []SlowAction{SlowDropped}},
+		{name: "disconnect отключает сразу", policy: DisconnectSlow, full: true, disconnect: true, queued: 1},
+		{name: "block откладывает событие", policy: BlockSlow, full: true, queued: 1, actions: []SlowAction{SlowBlocked}},
+		{name: "block в остановленное подключение", policy: BlockSlow, full: true, stopped: true, queued: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := &slowLog{}
+			c := &chat{queue: Queue{Size: 1, Policy: tt.policy, BlockTimeout: time.Minute, OnSlow: log.observe}}
+			// писатель не запущен, очередь никто не разбирает
+			conn := &connection{queue: make(chan *chatdesc.ChatEvent, 1), kicked: make(chan error, 1), stop: make(chan struct{})}
+			if tt.full {
+				conn.queue <- event(1)
+			}
+			if tt.stopped {
+				conn.close(nil)
+			}
+
+			if disconnect := c.enqueue(conn, event(2)); disconnect != tt.disconnect {
+				t.Fatalf("disconnect = %v, want %v", disconnect, tt.disconnect)
+			}
+			if queued := (<-conn.queue).GetMessageCreated().GetId(); queued != tt.queued {
+				t.Fatalf("queued event = %d, want %d", queued, tt.queued)
+			}
+			if actions := log.get(); !reflect.DeepEqual(actions, tt.actions) {
+				t.Fatalf("actions = %v, want %v", actions, tt.actions)
+			}
+		})
+	}
+}
+
+// blockedConnection подключение с политикой BlockSlow без писателя, очередь на одно событие уже занята
+func blockedConnection(timeout time.Duration) (*chat, *connection, *slowLog) {
+	log := &slowLog{}
+	c := &chat{queue: Queue{Size: 1, Policy: BlockSlow, BlockTimeout: timeout, OnSlow: log.observe}}
+	conn := &connection{queue: make(chan *chatdesc.ChatEvent, 1), kicked: make(chan error, 1), stop: make(chan struct{})}
+	conn.queue <- event(1)
+
+	return c, conn, log
+}
+
+func TestEnqueueBlockKeepsOrder(t *testing.T) {
+	c := &chat{queue: Queue{Size: 2, Policy: BlockSlow, BlockTimeout: time.Minute}}
+	conn := &connection{queue: make(chan *chatdesc.ChatEvent, 2), kicked: make(chan error, 1), stop: make(chan struct{})}
+
+	// очередь заполняется, третье событие откладывается, а четвертое встает за ним, хотя место в очереди уже есть
+	for id := int64(1); id <= 3; id++ {
+		if c.enqueue(conn, event(id)) {
+			t.Fatalf("subscriber disconnected on event %d", id)
+		}
+	}
+	if got, _ := conn.next(); got.GetMessageCreated().GetId() != 1 {
+		t.Fatalf("first event = %d, want 1", got.GetMessageCreated().GetId())
+	}
+	if c.enqueue(conn, event(4)) {
+		t.Fatal("subscriber disconnected on event 4")
+	}
+
+	// отложить можно не больше размера очереди
+	if !c.enqueue(conn, event(5)) {
+		t.Fatal("subscriber must be disconnected when too many events are pending")
+	}
+
+	for _, want := range []int64{2, 3, 4} {
+		got, ok := conn.next()
+		if !ok {
+			t.Fatalf("event %d was lost", want)
+		}
+		if id := got.GetMessageCreated().GetId(); id != want {
+			t.Fatalf("event = %d, want %d", id, want)
+		}
+	}
+	if _, ok := conn.next(); ok {
+		t.Fatal("unexpected event")
+	}
+	if conn.blocked != nil {
+		t.Fatal("block timer must be stopped when pending events are sent")
+	}
+}
+
+func TestEnqueueBlockTimesOut(t *testing.T) {
+	c, conn, log := blockedConnection(10 * time.Millisecond)
+
+	if c.enqueue(conn, event(2)) {
+		t.Fatal("blocked subscriber must not be disconnected at once")
+	}
+
+	select {
+	case err := <-conn.kicked:
+		if !errors.Is(err, ErrSlowConsumer) {
+			t.Fatalf("kick reason = %v, want %v", err, ErrSlowConsumer)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("blocked subscriber was not disconnected")
+	}
+
+	want := []SlowAction{SlowBlocked, SlowTimedOut, SlowDisconnected}
+	deadline := time.Now().Add(time.Second)
+	for !reflect.DeepEqual(log.get(), want) {
+		if time.Now().After(deadline) {
+			t.Fatalf("actions = %v, want %v", log.get(), want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestEnqueueBlockExtendedByWriter(t *testing.T) {
+	const timeout = 100 * time.Millisecond
+	c, conn, _ := blockedConnection(timeout)
+
+	if c.enqueue(conn, event(2)) {
+		t.Fatal("blocked subscriber must not be disconnected at once")
+	}
+
+	// писатель забирает событие раньше таймаута, отсчет начинается заново
+	time.Sleep(timeout * 6 / 10)
+	if _, ok := conn.next(); !ok {
+		t.Fatal("queued event was lost")
+	}
+	time.Sleep(timeout * 6 / 10)
+
+	select {
+	case err := <-conn.kicked:
+		t.Fatalf("subscriber kicked while the writer makes progress: %v", err)
+	default:
+	}
+
+	select {
+	case <-conn.kicked:
+	case <-time.After(time.Second):
+		t.Fatal("stalled subscriber was not disconnected")
+	}
+}
+
+func TestChatKicksSlowConsumer(t *testing.T) {
+	c := NewChat(1, time.Minute, time.Minute, Queue{Size: 1, Policy: DisconnectSlow}, func(Chat) {})
+	defer c.Close()
+	go c.Run()
+
+	fast := newFakeStream(10, false)
+	slow := newFakeStream(10, true)
+	defer close(slow.block)
+
+	_, fastKicked := c.Connect(1, fast)
+	_, slowKicked := c.Connect(2, slow)
+
+	// писатель медленного забирает первое событие и зависает на Send, второе занимает очередь, третье не влезает.
+	// Следующее событие отправляем, когда быстрый получил предыдущее, чтобы его очередь не переполнялась
+	for id := int64(1); id <= 3; id++ {
+		if !c.AddEvent(event(id)) {
+			t.Fatalf("event %d dropped", id)
+		}
+
+		select {
+		case e := <-fast.events:
+			if got := e.GetMessageCreated().GetId(); got != id {
+				t.Fatalf("fast subscriber got event %d, want %d", got, id)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("fast subscriber did not get event %d", id)
+		}
+	}
+
+	select {
+	case err := <-slowKicked:
+		if !errors.Is(err, ErrSlowConsumer) {
+			t.Fatalf("kick reason = %v, want %v", err, ErrSlowConsumer)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("slow consumer was not disconnected")
+	}
+
+	select {
+	case err := <-fastKicked:
+		t.Fatalf("fast subscriber kicked: %v", err)
+	default:
+	}
+}
+
+func TestChatBlockDoesNotDelayOthers(t *testing.T) {
+	c := NewChat(1, time.Minute, time.Minute, Queue{Size: 2, Policy: BlockSlow, BlockTimeout: time.Minute}, func(Chat) {})
+	defer c.Close()
+	go c.Run()
+
+	fast := newFakeStream(10, false)
+	slow := newFakeStream(10, true)
+	defer close(slow.block)
+
+	_, fastKicked := c.Connect(1, fast)
+	_, slowKicked := c.Connect(2, slow)
+
+	// медленный не принимает ничего, его события ждут в очереди и в pending, а быстрый получает каждое сразу
+	for id := int64(1); id <= 3; id++ {
+		if !c.AddEvent(event(id)) {
+			t.Fatalf("event %d dropped", id)
+		}
+
+		select {
+		case e := <-fast.events:
+			if got := e.GetMessageCreated().GetId(); got != id {
+				t.Fatalf("fast subscriber got event %d, want %d", got, id)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("fast subscriber did not get event %d", id)
+		}
+	}
+
+	select {
+	case err := <-fastKicked:
+		t.Fatalf("fast subscriber kicked: %v", err)
+	case err := <-slowKicked:
+		t.Fatalf("slow subscriber kicked before the block timeout: %v", err)
+	default:
+	}
+}
@@ -0,0 +1,77 @@
+package streaming
+
+import (
+	"sync"
+
+	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
+)
+
+// ReplayStream обертка над стримом клиента, которая копит живые события чата, пока клиенту досылается история.
+// Так при переключении с истории на живые сообщения не будет ни пропусков, ни дублей
+type ReplayStream struct {
+	Stream
+	m       sync.Mutex
+	live    bool
+	pending []*chatdesc.ChatEvent
+	// sent id уже отправленных из истории сообщений, чтобы не задублировать их из накопленных живых событий
+	sent map[int64]struct{}
+}
+
+// NewReplayStream Создает обертку, до вызова Replay события не отправляются, а копятся
+func NewReplayStream(stream Stream) *ReplayStream {
+	return &ReplayStream{Stream: stream, sent: make(map[int64]struct{})}
+}
+
+// Send Отправляет событие клиенту или откладывает его, если история еще не отправлена
+func (s *ReplayStream) Send(event *chatdesc.ChatEvent) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if !s.live {
+		s.pending = append(s.pending, event)
+		return nil
+	}
+
+	return s.Stream.Send(event)
+}
+
+// SendHistory Отправляет очередную страницу истории, живые события пока продолжают копиться
+func (s *ReplayStream) SendHistory(history []*chatdesc.ChatEvent) error {
+	for _, event := range history {
+		if err := s.Stream.Send(event); err != nil {
+			return err
+		}
+		s.sent[event.GetMessageCreated().GetId()] = struct{}{}
+	}
+
+	return nil
+}
+
+// Replay Отправляет остаток истории, затем накопленные за это время события, кроме новых сообщений, которые уже были
+// в истории, и переключает стрим на живую рассылку
+func (s *ReplayStream) Replay(history []*chatdesc.ChatEvent) error {
+	if err := s.SendHistory(history); err != nil {
+		return err
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	for _, event := range s.pending {
+		if created := event.GetMessageCreated(); created != nil {
+			if _, ok := s.sent[created.GetId()]; ok {
+				continue
+			}
+		}
+
+		if err := s.Stream.Send(event); err != nil {
+			return err
+		}
+	}
+
+	s.pending = nil
+	s.sent = nil
+	s.live = true
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package grpc_server
+
+import (
+	"context"
+
+	"github.com/rkchv/chat/internal/services/models"
+	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
+)
+
+// UserDeleted сообщает чатам, что удаленный пользователь больше не участник, его стримы будут закрыты.
+// В чатах, которыми он владел, сообщает о новом владельце
+func (s *Server) UserDeleted(ctx context.Context, userId int64, removed *models.RemovedUser) {
+	for _, chatId := range removed.ChatIds {
+		s.broadcast(ctx, memberLeftEvent(chatId, userId, chatdesc.MemberLeftReason_MEMBER_LEFT_REASON_ACCOUNT_DELETED))
+
+		if ownerId, ok := removed.Owners[chatId]; ok {
+			s.broadcast(ctx, memberRoleChangedEvent(chatId, ownerId, chatdesc.MemberRole_MEMBER_ROLE_OWNER))
+		}
+	}
+}
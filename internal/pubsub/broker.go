@@ -0,0 +1,21 @@
+package pubsub
+
+import (
+	"context"
+
+	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
+)
+
+// Handler обработчик события чата, пришедшего от брокера
+type Handler func(event *chatdesc.ChatEvent)
+
+// Broker доставляет события чатов во все экземпляры сервиса, у каждого из которых свои подключенные клиенты
+type Broker interface {
+	Publish(ctx context.Context, event *chatdesc.ChatEvent) error
+	// Subscribe начинает получать события чата, вызывается, когда чат открывается в этом экземпляре
+	Subscribe(ctx context.Context, chatId int64) error
+	// Unsubscribe перестает получать события чата, когда в этом экземпляре его закрыли
+	Unsubscribe(ctx context.Context, chatId int64) error
+	// Run получает события и передает их в handler, блокируется до отмены контекста
+	Run(ctx context.Context, handler Handler) error
+}
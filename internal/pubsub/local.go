@@ -0,0 +1,50 @@
+package pubsub
+
+import (
+	"context"
+
+	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
+)
+
+const localBufferSize = 1024
+
+var _ Broker = (*localBroker)(nil)
+
+// localBroker брокер внутри процесса, подходит когда сервис запущен в одном экземпляре
+type localBroker struct {
+	events chan *chatdesc.ChatEvent
+}
+
+// NewLocalBroker Новый экземпляр брокера внутри процесса
+func NewLocalBroker() Broker {
+	return &localBroker{events: make(chan *chatdesc.ChatEvent, localBufferSize)}
+}
+
+func (b *localBroker) Publish(ctx context.Context, event *chatdesc.ChatEvent) error {
+	select {
+	case b.events <- event:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Subscribe все события и так проходят через этот процесс, подписываться не на что
+func (b *localBroker) Subscribe(_ context.Context, _ int64) error {
+	return nil
+}
+
+func (b *localBroker) Unsubscribe(_ context.Context, _ int64) error {
+	return nil
+}
+
+func (b *localBroker) Run(ctx context.Context, handler Handler) error {
+	for {
+		select {
+		case event := <-b.events:
+			handler(event)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
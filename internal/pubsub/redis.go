@@ -0,0 +1,144 @@
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/rkchv/chat/lib/redis"
+	"google.golang.org/protobuf/proto"
+
+	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
+)
+
+const channelPrefix = "chat:events:"
+
+const (
+	// resubscribeDelay пауза перед первой попыткой переподписаться после обрыва, дальше она удваивается
+	resubscribeDelay = 100 * time.Millisecond
+	// maxResubscribeDelay больше этого между попытками не ждем
+	maxResubscribeDelay = 5 * time.Second
+)
+
+var _ Broker = (*redisBroker)(nil)
+
+// redisBroker брокер поверх redis pub/sub, у каждого чата свой канал. Экземпляр подписан только на каналы
+// открытых в нем чатов, так что трафик чатов без местных подписчиков до него не доходит.
+// Подписка живет, пока живо соединение. После обрыва Run заводит новую и подписывает ее на все открытые чаты
+type redisBroker struct {
+	client redis.Client
+
+	m sync.Mutex
+	// sub текущая подписка, nil пока Run ее не завел или заводит заново
+	sub redis.Subscription
+	// chats чаты, на события которых экземпляр подписан
+	chats map[int64]struct{}
+}
+
+// NewRedisBroker Новый экземпляр брокера через redis
+func NewRedisBroker(client redis.Client) Broker {
+	return &redisBroker{client: client, chats: make(map[int64]struct{})}
+}
+
+func (b *redisBroker) Publish(ctx context.Context, event *chatdesc.ChatEvent) error {
+	data, err := proto.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return b.client.Publish(ctx, channel(event.GetChatId()), data)
+}
+
+// Subscribe запоминает чат и подписывает на него текущую подписку. Если подписки сейчас нет,
+// на чат подпишется следующая
+func (b *redisBroker) Subscribe(ctx context.Context, chatId int64) error {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	if b.sub != nil {
+		if err := b.sub.Subscribe(ctx, channel(chatId)); err != nil {
+			return err
+		}
+	}
+	b.chats[chatId] = struct{}{}
+
+	return nil
+}
+
+func (b *redisBroker) Unsubscribe(ctx context.Context, chatId int64) error {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	delete(b.chats, chatId)
+	if b.sub == nil {
+		return nil
+	}
+
+	return b.sub.Unsubscribe(ctx, channel(chatId))
+}
+
+// Run читает события каналов, на которые подписаны открытые здесь чаты. При обрыве соединения
+// переподписывается с нарастающей паузой, возвращается только после отмены контекста
+func (b *redisBroker) Run(ctx context.Context, handler Handler) error {
+	delay := resubscribeDelay
+	for {
+		sub, err := b.resubscribe(ctx)
+		if err == nil {
+			delay = resubscribeDelay
+			err = sub.Receive(ctx, func(channel string, data []byte) {
+				event := &chatdesc.ChatEvent{}
+				if err := proto.Unmarshal(data, event); err != nil {
+					log.Printf("failed to unmarshal chat event from %s: %v", channel, err)
+					return
+				}
+
+				handler(event)
+			})
+		}
+
+		b.m.Lock()
+		b.sub = nil
+		b.m.Unlock()
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		log.Printf("chat events subscription lost, resubscribing in %s: %v", delay, err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil
+		}
+		delay = min(delay*2, maxResubscribeDelay)
+	}
+}
+
+// resubscribe заводит новую подписку на все открытые чаты. Пока она не заведена, Subscribe и Unsubscribe ждут,
+// так что ни один чат не потеряется между ними
+func (b *redisBroker) resubscribe(ctx context.Context) (redis.Subscription, error) {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	sub := b.client.NewSubscription()
+	if len(b.chats) > 0 {
+		channels := make([]string, 0, len(b.chats))
+		for chatId := range b.chats {
+			channels = append(channels, channel(chatId))
+		}
+
+		if err := sub.Subscribe(ctx, channels...); err != nil {
+			_ = sub.Close()
+			return nil, err
+		}
+	}
+	b.sub = sub
+
+	return sub, nil
+}
+
+func channel(chatId int64) string {
+	return fmt.Sprintf("%s%d", channelPrefix, chatId)
+}
@@ -0,0 +1,319 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rkchv/chat/lib/redis"
+
+	chatdesc "github.com/rkchv/chat/pkg/chat_v1"
+)
+
+// fakeServer pub/sub в памяти, общий для нескольких экземпляров сервиса
+type fakeServer struct {
+	m    sync.Mutex
+	subs []*fakeSubscription
+}
+
+// fakeClient клиент к fakeServer, реализует только то, что нужно брокеру
+type fakeClient struct {
+	redis.Client
+	server *fakeServer
+}
+
+func (c *fakeClient) Publish(_ context.Context, channel string, message interface{}) error {
+	c.server.m.Lock()
+	defer c.server.m.Unlock()
+
+	for _, sub := range c.server.subs {
+		if sub.channels[channel] {
+			sub.messages <- fakeMessage{channel: channel, data: message.([]byte)}
+		}
+	}
+
+	return nil
+}
+
+func (c *fakeClient) NewSubscription() redis.Subscription {
+	sub := &fakeSubscription{
+		server:   c.server,
+		channels: make(map[string]bool),
+		messages: make(chan fakeMessage, 16),
+		closed:   make(chan struct{}),
+	}
+
+	c.server.m.Lock()
+	defer c.server.m.Unlock()
+	c.server.subs = append(c.server.subs, sub)
+
+	return sub
+}
+
+type fakeMessage struct {
+	channel string
+	data    []byte
+}
+
+// disconnect обрывает соединения всех подписок, как при перезапуске редиса
+func (s *fakeServer) disconnect() {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	for _, sub := range s.subs {
+		sub.close()
+	}
+	s.subs = nil
+}
+
+// subscribers сколько подписок сейчас подписано на канал
+func (s *fakeServer) subscribers(channel string) int {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	n := 0
+	for _, sub := range s.subs {
+		if sub.channels[channel] {
+			n++
+		}
+	}
+
+	return n
+}
+
+var errConnectionLost = errors.New("connection lost")
+
+type fakeSubscription struct {
+	server *fakeServer
+	// channels меняется под server.m
+	channels  map[string]bool
+	messages  chan fakeMessage
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (s *fakeSubscription) close() {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+	})
+}
+
+func (s *fakeSubscription) isClosed() bool {
+	select {
+	case <-s.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *fakeSubscription) Subscribe(_ context.Context, channels ...string) error {
+	s.server.m.Lock()
+	defer s.server.m.Unlock()
+	if s.isClosed() {
+		return errConnectionLost
+	}
+	for _, ch := range channels {
+		s.channels[ch] = true
+	}
+
+	return nil
+}
+
+func (s *fakeSubscription) Unsubscribe(_ context.Context, channels ...string) error {
+	s.server.m.Lock()
+	defer s.server.m.Unlock()
+	for _, ch := range channels {
+		delete(s.channels, ch)
+	}
+
+	return nil
+}
+
+func (s *fakeSubscription) Receive(ctx context.Context, handler redis.MessageHandler) error {
+	defer s.close()
+
+	for {
+		select {
+		case msg := <-s.messages:
+			handler(msg.channel, msg.data)
+		case <-s.closed:
+			return errConnectionLost
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (s *fakeSubscription) Close() error {
+	s.close()
+	return nil
+}
+
+// runBroker запускает брокер и возвращает канал с полученными им событиями
+func runBroker(ctx context.Context, b Broker) <-chan *chatdesc.ChatEvent {
+	events := make(chan *chatdesc.ChatEvent, 16)
+	go func() {
+		_ = b.Run(ctx, func(event *chatdesc.ChatEvent) {
+			events <- event
+		})
+	}()
+
+	return events
+}
+
+func TestRedisBrokerDeliversOnlySubscribedChats(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server := &fakeServer{}
+	a := NewRedisBroker(&fakeClient{server: server})
+	b := NewRedisBroker(&fakeClient{server: server})
+	aEvents := runBroker(ctx, a)
+	bEvents := runBroker(ctx, b)
+
+	if err := a.Subscribe(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Subscribe(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	// подписки заводит Run, до этого события чатов никто не слушает
+	waitSubscribed(t, server, channel(1))
+	waitSubscribed(t, server, channel(2))
+
+	tests := []struct {
+		name     string
+		chatId   int64
+		receiver <-chan *chatdesc.ChatEvent
+		other    <-chan *chatdesc.ChatEvent
+	}{
+		{name: "событие чата 1 только экземпляру a", chatId: 1, receiver: aEvents, other: bEvents},
+		{name: "событие чата 2 только экземпляру b", chatId: 2, receiver: bEvents, other: aEvents},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// публикует экземпляр, на котором чат не открыт
+			if err := b.Publish(ctx, &chatdesc.ChatEvent{ChatId: tt.chatId}); err != nil {
+				t.Fatal(err)
+			}
+
+			select {
+			case event := <-tt.receiver:
+				if event.GetChatId() != tt.chatId {
+					t.Fatalf("chat id = %d, want %d", event.GetChatId(), tt.chatId)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("event was not delivered")
+			}
+
+			select {
+			case event := <-tt.other:
+				t.Fatalf("unsubscribed instance got event of chat %d", event.GetChatId())
+			default:
+			}
+		})
+	}
+}
+
+func TestRedisBrokerUnsubscribe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server := &fakeServer{}
+	b := NewRedisBroker(&fakeClient{server: server})
+	events := runBroker(ctx, b)
+
+	if err := b.Subscribe(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Unsubscribe(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Publish(ctx, &chatdesc.ChatEvent{ChatId: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case event := <-events:
+		t.Fatalf("got event of closed chat %d", event.GetChatId())
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestRedisBrokerResubscribesAfterDisconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server := &fakeServer{}
+	b := NewRedisBroker(&fakeClient{server: server})
+
+	// чат открыт до запуска Run, на него подпишется первая же подписка
+	if err := b.Subscribe(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	events := runBroker(ctx, b)
+	waitSubscribed(t, server, channel(1))
+
+	server.disconnect()
+	waitSubscribed(t, server, channel(1))
+
+	// чат, открытый после переподписки, тоже получает события
+	if err := b.Subscribe(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, chatId := range []int64{1, 2} {
+		if err := b.Publish(ctx, &chatdesc.ChatEvent{ChatId: chatId}); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case event := <-events:
+			if event.GetChatId() != chatId {
+				t.Fatalf("chat id = %d, want %d", event.GetChatId(), chatId)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event of chat %d was not delivered after reconnect", chatId)
+		}
+	}
+}
+
+func TestRedisBrokerRunStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	server := &fakeServer{}
+	b := NewRedisBroker(&fakeClient{server: server})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- b.Run(ctx, func(*chatdesc.ChatEvent) {})
+	}()
+
+	server.disconnect()
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run returned %v after cancel", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not stop after cancel")
+	}
+}
+
+// waitSubscribed ждет, пока на канал подпишется ровно одна подписка
+func waitSubscribed(t *testing.T, server *fakeServer, channel string) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for server.subscribers(channel) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("channel %s has %d subscribers, want 1", channel, server.subscribers(channel))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
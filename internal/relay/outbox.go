@@ -0,0 +1,163 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/rkchv/chat/lib/db"
+	"github.com/rkchv/chat/lib/kafka"
+
+	"github.com/rkchv/chat/internal/repository"
+)
+
+const (
+	// pruneInterval как часто удалять опубликованные события старше срока хранения
+	pruneInterval = time.Hour
+	// pruneBatchSize сколько событий удалять одним запросом, чтобы не держать долгих блокировок
+	pruneBatchSize = 1000
+)
+
+// errSendTimeout kafka не подтвердила пачку за отведенное время
+var errSendTimeout = errors.New("kafka send timed out")
+
+// OutboxRelay переносит события из outbox в kafka. Выборка, отправка и отметка об отправке идут в одной транзакции,
+// поэтому событие не потеряется, но при сбое после отправки может быть доставлено повторно (at-least-once).
+// Публикует только один экземпляр за раз, иначе пачки параллельных реплик перемешали бы события одного чата.
+// Выбранные строки заблокированы, пока kafka не ответит. Добавлению новых событий это не мешает, но транзакция
+// держит снимок базы и advisory-блокировку, поэтому отправка ограничена sendTimeout
+type OutboxRelay struct {
+	txManager   db.Transactor
+	outbox      repository.OutboxRepository
+	producer    kafka.Producer
+	interval    time.Duration
+	batchSize   uint64
+	sendTimeout time.Duration
+	retention   time.Duration
+}
+
+func NewOutboxRelay(
+	txManager db.Transactor,
+	outbox repository.OutboxRepository,
+	producer kafka.Producer,
+	interval time.Duration,
+	batchSize uint64,
+	sendTimeout time.Duration,
+	retention time.Duration,
+) *OutboxRelay {
+	return &OutboxRelay{
+		txManager:   txManager,
+		outbox:      outbox,
+		producer:    producer,
+		interval:    interval,
+		batchSize:   batchSize,
+		sendTimeout: sendTimeout,
+		retention:   retention,
+	}
+}
+
+// Run публикует события и удаляет опубликованные старше срока хранения до отмены контекста
+func (r *OutboxRelay) Run(ctx context.Context) {
+	t := time.NewTicker(r.interval)
+	defer t.Stop()
+	prune := time.NewTicker(pruneInterval)
+	defer prune.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			// пока выбирается полная пачка, события копятся быстрее, чем публикуются - не ждем следующего тика
+			for {
+				sent, err := r.publishBatch(ctx)
+				if err != nil {
+					log.Printf("failed to publish outbox events: %v", err)
+					break
+				}
+
+				if sent < r.batchSize {
+					break
+				}
+			}
+		case now := <-prune.C:
+			if err := r.prune(ctx, now.Add(-r.retention)); err != nil {
+				log.Printf("failed to prune published outbox events: %v", err)
+			}
+		}
+	}
+}
+
+func (r *OutboxRelay) publishBatch(ctx context.Context) (uint64, error) {
+	var sent uint64
+	err := r.txManager.ReadCommitted(ctx, func(ctx context.Context) error {
+		locked, err := r.outbox.TryLock(ctx)
+		if err != nil || !locked {
+			return err
+		}
+
+		events, err := r.outbox.FetchUnpublished(ctx, r.batchSize)
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		messages := make([]kafka.Message, 0, len(events))
+		ids := make([]int64, 0, len(events))
+		for _, e := range events {
+			messages = append(messages, kafka.Message{
+				Topic: e.Topic,
+				Key:   e.Key,
+				Value: e.Payload,
+				// по номеру потребители отбрасывают повторы и замечают пропуски в событиях чата
+				Headers: map[string]string{"type": e.Type, "seq": strconv.FormatInt(e.Seq, 10)},
+			})
+			ids = append(ids, e.Id)
+		}
+
+		if err = r.send(ctx, messages); err != nil {
+			return err
+		}
+
+		sent = uint64(len(events))
+		return r.outbox.MarkPublished(ctx, ids)
+	})
+
+	return sent, err
+}
+
+// send отправляет пачку, но ждет не дольше sendTimeout. Если kafka не ответила, транзакция откатывается,
+// и пачка будет отправлена заново, даже если kafka все-таки ее примет
+func (r *OutboxRelay) send(ctx context.Context, messages []kafka.Message) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- r.producer.Send(messages...)
+	}()
+
+	t := time.NewTimer(r.sendTimeout)
+	defer t.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-t.C:
+		return errSendTimeout
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// prune удаляет опубликованные раньше before события небольшими пачками
+func (r *OutboxRelay) prune(ctx context.Context, before time.Time) error {
+	for {
+		deleted, err := r.outbox.DeletePublished(ctx, before, pruneBatchSize)
+		if err != nil {
+			return err
+		}
+
+		if deleted < pruneBatchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rkchv/chat/lib/db"
+	"github.com/rkchv/chat/lib/kafka"
+
+	"github.com/rkchv/chat/internal/domain/outbox"
+	"github.com/rkchv/chat/internal/repository"
+)
+
+// fakeTx выполняет транзакцию сразу, без отката
+type fakeTx struct{}
+
+func (fakeTx) BeginTx(_ context.Context, _ pgx.TxOptions) (pgx.Tx, error) {
+	return nil, errors.New("transactions are not supported by fakeTx")
+}
+
+func (fakeTx) ReadCommitted(ctx context.Context, f db.Handler) error {
+	return f(ctx)
+}
+
+type fakeOutbox struct {
+	repository.OutboxRepository
+	locked    bool
+	events    []*outbox.Event
+	published []int64
+	// deleted сколько событий удалит каждый следующий вызов DeletePublished
+	deleted []int64
+	prunes  int
+}
+
+func (f *fakeOutbox) TryLock(_ context.Context) (bool, error) {
+	return f.locked, nil
+}
+
+func (f *fakeOutbox) FetchUnpublished(_ context.Context, limit uint64) ([]*outbox.Event, error) {
+	return f.events[:min(uint64(len(f.events)), limit)], nil
+}
+
+func (f *fakeOutbox) MarkPublished(_ context.Context, ids []int64) error {
+	f.published = append(f.published, ids...)
+	return nil
+}
+
+func (f *fakeOutbox) DeletePublished(_ context.Context, _ time.Time, _ uint64) (int64, error) {
+	f.prunes++
+	if len(f.deleted) == 0 {
+		return 0, nil
+	}
+
+	n := f.deleted[0]
+	f.deleted = f.deleted[1:]
+
+	return n, nil
+}
+
+type fakeProducer struct {
+	sent  []kafka.Message
+	err   error
+	delay time.Duration
+}
+
+func (p *fakeProducer) Send(messages ...kafka.Message) error {
+	time.Sleep(p.delay)
+	if p.err != nil {
+		return p.err
+	}
+	p.sent = append(p.sent, messages...)
+
+	return nil
+}
+
+func (p *fakeProducer) Close() error {
+	return nil
+}
+
+func TestPublishBatch(t *testing.T) {
+	events := []*outbox.Event{
+		{Id: 1, Topic: outbox.TopicMessages, Key: "1", Type: outbox.MessageCreated, Seq: 7},
+		{Id: 2, Topic: outbox.TopicMembers, Key: "2", Type: outbox.MemberJoined, Seq: 1},
+		{Id: 3, Topic: outbox.TopicMessages, Key: "1", Type: outbox.MessageEdited, Seq: 8},
+	}
+	errKafka := errors.New("kafka is down")
+
+	tests := []struct {
+		name      string
+		locked    bool
+		producer  *fakeProducer
+		err       error
+		sent      uint64
+		published []int64
+	}{
+		{name: "публикует в порядке выборки и отмечает", locked: true, producer: &fakeProducer{}, sent: 3, published: []int64{1, 2, 3}},
+		{name: "публикует другой экземпляр", locked: false, producer: &fakeProducer{}},
+		{name: "kafka недоступна", locked: true, producer: &fakeProducer{err: errKafka}, err: errKafka},
+		{name: "kafka не ответила вовремя", locked: true, producer: &fakeProducer{delay: 200 * time.Millisecond}, err: errSendTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeOutbox{locked: tt.locked, events: events}
+			r := NewOutboxRelay(fakeTx{}, repo, tt.producer, time.Second, 10, 50*time.Millisecond, time.Hour)
+
+			sent, err := r.publishBatch(context.Background())
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if sent != tt.sent {
+				t.Fatalf("sent = %d, want %d", sent, tt.sent)
+			}
+			if !reflect.DeepEqual(repo.published, tt.published) {
+				t.Fatalf("published = %v, want %v", repo.published, tt.published)
+			}
+			if tt.sent == 0 {
+				return
+			}
+
+			var seqs []string
+			for i, m := range tt.producer.sent {
+				if m.Key != events[i].Key || m.Headers["type"] != events[i].Type {
+					t.Fatalf("message %d = %+v, want event %+v", i, m, events[i])
+				}
+				seqs = append(seqs, m.Headers["seq"])
+			}
+			if !reflect.DeepEqual(seqs, []string{"7", "1", "8"}) {
+				t.Fatalf("seq headers = %v", seqs)
+			}
+		})
+	}
+}
+
+func TestPrune(t *testing.T) {
+	tests := []struct {
+		name    string
+		deleted []int64
+		prunes  int
+	}{
+		{name: "нечего удалять", deleted: nil, prunes: 1},
+		{name: "неполная пачка - последняя", deleted: []int64{10}, prunes: 1},
+		{name: "полные пачки удаляются до неполной", deleted: []int64{pruneBatchSize, pruneBatchSize, 5}, prunes: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeOutbox{deleted: tt.deleted}
+			r := NewOutboxRelay(fakeTx{}, repo, &fakeProducer{}, time.Second, 10, time.Second, time.Hour)
+
+			if err := r.prune(context.Background(), time.Now()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if repo.prunes != tt.prunes {
+				t.Fatalf("prunes = %d, want %d", repo.prunes, tt.prunes)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/rkchv/chat/lib/db"
+
+	"github.com/rkchv/chat/internal/domain/message"
+	"github.com/rkchv/chat/internal/repository"
+	"github.com/rkchv/chat/internal/repository/postgres/model"
+)
+
+const (
+	attachmentsChatIdColumn     = "chat_id"
+	attachmentsMessageIdColumn  = "message_id"
+	attachmentsUploadedByColumn = "uploaded_by"
+	attachmentsBlobKeyColumn    = "blob_key"
+	attachmentsFileNameColumn   = "file_name"
+	attachmentsMimeTypeColumn   = "mime_type"
+	attachmentsSizeColumn       = "size"
+)
+
+var attachmentsColumns = []string{
+	idColumn,
+	attachmentsChatIdColumn,
+	attachmentsMessageIdColumn,
+	attachmentsUploadedByColumn,
+	attachmentsBlobKeyColumn,
+	attachmentsFileNameColumn,
+	attachmentsMimeTypeColumn,
+	attachmentsSizeColumn,
+	createdColumn,
+}
+
+var _ repository.AttachmentRepository = (*attachmentRepo)(nil)
+
+type attachmentRepo struct {
+	conn db.Client
+}
+
+func NewAttachmentRepository(conn db.Client) repository.AttachmentRepository {
+	return &attachmentRepo{conn: conn}
+}
+
+func (r *attachmentRepo) Save(ctx context.Context, a *message.Attachment) error {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Insert("chat.attachments").
+		Columns(attachmentsChatIdColumn, attachmentsUploadedByColumn, attachmentsBlobKeyColumn, attachmentsFileNameColumn,
+			attachmentsMimeTypeColumn, attachmentsSizeColumn, createdColumn).
+		Values(a.ChatId, a.UploadedBy, a.BlobKey, a.FileName, a.MimeType, a.Size, a.CreatedAt).
+		Suffix("RETURNING " + idColumn).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	q := db.Query{Name: "repository.postgres.attachment.Save", QueryRaw: sql}
+
+	return r.conn.DB().QueryRow(ctx, q, args...).Scan(&a.Id)
+}
+
+func (r *attachmentRepo) Get(ctx context.Context, id int64) (*message.Attachment, error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Select(attachmentsColumns...).
+		From("chat.attachments").
+		Where(sq.Eq{idColumn: id}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.DB().Query(ctx, db.Query{Name: "repository.postgres.attachment.Get", QueryRaw: sql}, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dto, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.AttachmentDTO])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+
+	return toDomainAttachment(dto), nil
+}
+
+func (r *attachmentRepo) Attach(ctx context.Context, msg *message.Message, ids []int64) ([]message.Attachment, error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Update("chat.attachments").
+		Set(attachmentsMessageIdColumn, msg.Id).
+		Where(sq.Eq{
+			idColumn:                    ids,
+			attachmentsChatIdColumn:     msg.ChatId,
+			attachmentsUploadedByColumn: msg.UserId,
+			attachmentsMessageIdColumn:  nil,
+		}).
+		Suffix("RETURNING " + strings.Join(attachmentsColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.DB().Query(ctx, db.Query{Name: "repository.postgres.attachment.Attach", QueryRaw: sql}, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dtos, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.AttachmentDTO])
+	if err != nil {
+		return nil, err
+	}
+
+	attached := make([]message.Attachment, 0, len(dtos))
+	for _, dto := range dtos {
+		attached = append(attached, *toDomainAttachment(dto))
+	}
+
+	return attached, nil
+}
+
+func (r *attachmentRepo) DeleteUnattached(ctx context.Context, before time.Time, limit uint64) ([]message.Attachment, error) {
+	stale := sq.And{sq.Eq{attachmentsMessageIdColumn: nil}, sq.Lt{createdColumn: before}}
+	batch := sq.Select(idColumn).
+		From("chat.attachments").
+		Where(stale).
+		Limit(limit)
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	// условие повторяется в самом удалении: файл, прикрепленный параллельно, после блокировки строки уже не подходит
+	sql, args, err := psql.Delete("chat.attachments").
+		Where(stale).
+		Where(sq.Expr(idColumn+" IN (?)", batch)).
+		Suffix("RETURNING " + strings.Join(attachmentsColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.DB().Query(ctx, db.Query{Name: "repository.postgres.attachment.DeleteUnattached", QueryRaw: sql}, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dtos, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.AttachmentDTO])
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := make([]message.Attachment, 0, len(dtos))
+	for _, dto := range dtos {
+		deleted = append(deleted, *toDomainAttachment(dto))
+	}
+
+	return deleted, nil
+}
+
+func (r *attachmentRepo) Unreferenced(ctx context.Context, keys []string) ([]string, error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Select(attachmentsBlobKeyColumn).
+		Distinct().
+		From("chat.attachments").
+		Where(sq.Eq{attachmentsBlobKeyColumn: keys}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.DB().Query(ctx, db.Query{Name: "repository.postgres.attachment.Unreferenced", QueryRaw: sql}, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	referenced, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+
+	unreferenced := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if !slices.Contains(referenced, key) {
+			unreferenced = append(unreferenced, key)
+		}
+	}
+
+	return unreferenced, nil
+}
+
+// loadAttachments одним запросом дополняет сообщения их вложениями
+func loadAttachments(ctx context.Context, conn db.Client, messages []*message.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	byId := make(map[int64]*message.Message, len(messages))
+	ids := make([]int64, 0, len(messages))
+	for _, msg := range messages {
+		byId[msg.Id] = msg
+		ids = append(ids, msg.Id)
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Select(attachmentsColumns...).
+		From("chat.attachments").
+		Where(sq.Eq{attachmentsMessageIdColumn: ids}).
+		OrderBy(idColumn).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	rows, err := conn.DB().Query(ctx, db.Query{Name: "repository.postgres.attachment.ForMessages", QueryRaw: sql}, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	dtos, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.AttachmentDTO])
+	if err != nil {
+		return err
+	}
+
+	for _, dto := range dtos {
+		a := toDomainAttachment(dto)
+		msg := byId[a.MessageId]
+		msg.Attachments = append(msg.Attachments, *a)
+	}
+
+	return nil
+}
+
+func toDomainAttachment(dto model.AttachmentDTO) *message.Attachment {
+	a := &message.Attachment{
+		Id:         dto.Id,
+		ChatId:     dto.ChatId,
+		UploadedBy: dto.UploadedBy,
+		BlobKey:    dto.BlobKey,
+		FileName:   dto.FileName,
+		MimeType:   dto.MimeType,
+		Size:       dto.Size,
+		CreatedAt:  dto.CreatedAt,
+	}
+	if dto.MessageId != nil {
+		a.MessageId = *dto.MessageId
+	}
+
+	return a
+}
@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAttachmentDeleteUnattached(t *testing.T) {
+	client, fake := newFakeClient()
+	r := NewAttachmentRepository(client)
+	before := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	if _, err := r.DeleteUnattached(context.Background(), before, 100); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	q, ok := fake.query("repository.postgres.attachment.DeleteUnattached")
+	if !ok {
+		t.Fatal("delete query was not executed")
+	}
+
+	// условие проверяется и в выборке пачки, и в самом удалении
+	stale := "message_id IS NULL AND created_at <"
+	if strings.Count(q.sql, stale) != 2 {
+		t.Fatalf("query %q must check %q on the deleted rows too", q.sql, stale)
+	}
+	if !strings.HasPrefix(q.sql, "DELETE FROM chat.attachments WHERE") || !strings.Contains(q.sql, "LIMIT 100)") {
+		t.Fatalf("query %q does not delete a limited batch", q.sql)
+	}
+	if len(q.args) != 2 || q.args[0] != before || q.args[1] != before {
+		t.Fatalf("args = %v, want the cutoff twice", q.args)
+	}
+}
+
+func TestAttachmentUnreferenced(t *testing.T) {
+	client, fake := newFakeClient()
+	r := NewAttachmentRepository(client)
+
+	// в базе ссылок нет - все ключи свободны
+	keys, err := r.Unreferenced(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(keys, []string{"a", "b"}) {
+		t.Fatalf("unreferenced = %v, want [a b]", keys)
+	}
+
+	q, _ := fake.query("repository.postgres.attachment.Unreferenced")
+	if q.sql != "SELECT DISTINCT blob_key FROM chat.attachments WHERE blob_key IN ($1,$2)" {
+		t.Fatalf("query = %q", q.sql)
+	}
+}
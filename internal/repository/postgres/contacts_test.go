@@ -0,0 +1,27 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContactsQuery(t *testing.T) {
+	client, fake := newFakeClient()
+	r := New(client)
+
+	if _, err := r.Contacts(context.Background(), 10, []int64{20, 30}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	q, ok := fake.query("repository.postgres.Contacts")
+	if !ok {
+		t.Fatal("contacts query was not executed")
+	}
+
+	want := "SELECT DISTINCT other.user_id FROM chat.chat_users AS me " +
+		"JOIN chat.chat_users AS other ON other.chat_id = me.chat_id " +
+		"WHERE me.user_id = $1 AND other.user_id IN ($2,$3)"
+	if q.sql != want {
+		t.Fatalf("query = %q, want %q", q.sql, want)
+	}
+}
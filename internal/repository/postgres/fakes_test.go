@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rkchv/chat/lib/db"
+)
+
+// executed запрос, который репозиторий отправил в базу
+type executed struct {
+	name string
+	sql  string
+	args []interface{}
+}
+
+// fakeDB записывает запросы вместо выполнения. Query возвращает пустую выборку, QueryRow - значения из rows по имени
+// запроса или pgx.ErrNoRows, Exec - rowsAffected затронутых строк
+type fakeDB struct {
+	queries      []executed
+	rows         map[string][]interface{}
+	rowsAffected int64
+}
+
+type fakeClient struct {
+	db *fakeDB
+}
+
+func newFakeClient() (*fakeClient, *fakeDB) {
+	d := &fakeDB{rows: make(map[string][]interface{})}
+	return &fakeClient{db: d}, d
+}
+
+func (c *fakeClient) DB() db.DB {
+	return c.db
+}
+
+func (c *fakeClient) Close() error {
+	return nil
+}
+
+// query последний запрос с таким именем
+func (d *fakeDB) query(name string) (executed, bool) {
+	for i := len(d.queries) - 1; i >= 0; i-- {
+		if d.queries[i].name == name {
+			return d.queries[i], true
+		}
+	}
+
+	return executed{}, false
+}
+
+func (d *fakeDB) record(q db.Query, args []interface{}) {
+	d.queries = append(d.queries, executed{name: q.Name, sql: q.QueryRaw, args: args})
+}
+
+func (d *fakeDB) Exec(_ context.Context, q db.Query, args ...interface{}) (pgconn.CommandTag, error) {
+	d.record(q, args)
+	return pgconn.NewCommandTag(fmt.Sprintf("UPDATE %d", d.rowsAffected)), nil
+}
+
+func (d *fakeDB) Query(_ context.Context, q db.Query, args ...interface{}) (pgx.Rows, error) {
+	d.record(q, args)
+	return emptyRows{}, nil
+}
+
+func (d *fakeDB) QueryRow(_ context.Context, q db.Query, args ...interface{}) pgx.Row {
+	d.record(q, args)
+	values, ok := d.rows[q.Name]
+	if !ok {
+		return fakeRow{err: pgx.ErrNoRows}
+	}
+
+	return fakeRow{values: values}
+}
+
+func (d *fakeDB) SetQueryLogger(_ db.QueryLogger) {}
+
+func (d *fakeDB) BeginTx(_ context.Context, _ pgx.TxOptions) (pgx.Tx, error) {
+	return nil, errors.New("transactions are not supported by fakeDB")
+}
+
+func (d *fakeDB) ReadCommitted(ctx context.Context, f db.Handler) error {
+	return f(ctx)
+}
+
+func (d *fakeDB) Ping(_ context.Context) error {
+	return nil
+}
+
+func (d *fakeDB) Close() {}
+
+type fakeRow struct {
+	values []interface{}
+	err    error
+}
+
+func (r fakeRow) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	if len(dest) != len(r.values) {
+		return fmt.Errorf("scan %d values into %d destinations", len(r.values), len(dest))
+	}
+
+	for i, v := range r.values {
+		switch d := dest[i].(type) {
+		case *int64:
+			*d = v.(int64)
+		case *bool:
+			*d = v.(bool)
+		default:
+			return fmt.Errorf("unsupported scan destination %T", dest[i])
+		}
+	}
+
+	return nil
+}
+
+// emptyRows выборка без строк
+type emptyRows struct{}
+
+func (emptyRows) Close()                                       {}
+func (emptyRows) Err() error                                   { return nil }
+func (emptyRows) CommandTag() pgconn.CommandTag                { return pgconn.NewCommandTag("SELECT 0") }
+func (emptyRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (emptyRows) Next() bool                                   { return false }
+func (emptyRows) Scan(_ ...interface{}) error                  { return pgx.ErrNoRows }
+func (emptyRows) Values() ([]interface{}, error)               { return nil, nil }
+func (emptyRows) RawValues() [][]byte                          { return nil }
+func (emptyRows) Conn() *pgx.Conn                              { return nil }
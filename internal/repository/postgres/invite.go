@@ -0,0 +1,180 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/rkchv/chat/lib/db"
+
+	"github.com/rkchv/chat/internal/domain/chat"
+	"github.com/rkchv/chat/internal/domain/invite"
+	"github.com/rkchv/chat/internal/repository"
+	"github.com/rkchv/chat/internal/repository/postgres/model"
+)
+
+const (
+	invitesCodeColumn      = "code"
+	invitesChatIdColumn    = "chat_id"
+	invitesCreatedByColumn = "created_by"
+	invitesRoleColumn      = "role"
+	invitesMaxUsesColumn   = "max_uses"
+	invitesUsesColumn      = "uses"
+	invitesExpiresAtColumn = "expires_at"
+	invitesRevokedAtColumn = "revoked_at"
+
+	inviteUsesInviteIdColumn = "invite_id"
+	inviteUsesUserIdColumn   = "user_id"
+	inviteUsesUsedAtColumn   = "used_at"
+)
+
+var invitesColumns = []string{
+	idColumn,
+	invitesCodeColumn,
+	invitesChatIdColumn,
+	invitesCreatedByColumn,
+	invitesRoleColumn,
+	invitesMaxUsesColumn,
+	invitesUsesColumn,
+	invitesExpiresAtColumn,
+	invitesRevokedAtColumn,
+	createdColumn,
+}
+
+var _ repository.InviteRepository = (*inviteRepo)(nil)
+
+type inviteRepo struct {
+	conn db.Client
+}
+
+func NewInviteRepository(conn db.Client) repository.InviteRepository {
+	return &inviteRepo{conn: conn}
+}
+
+func (r *inviteRepo) Save(ctx context.Context, inv *invite.Invite) error {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Insert("chat.invites").
+		Columns(invitesCodeColumn, invitesChatIdColumn, invitesCreatedByColumn, invitesRoleColumn, invitesMaxUsesColumn, invitesExpiresAtColumn, createdColumn).
+		Values(inv.Code, inv.ChatId, inv.CreatedBy, string(inv.Role), inv.MaxUses, nullTime(inv.ExpiresAt), inv.CreatedAt).
+		Suffix("RETURNING " + idColumn).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	q := db.Query{Name: "repository.postgres.invite.Save", QueryRaw: sql}
+
+	return r.conn.DB().QueryRow(ctx, q, args...).Scan(&inv.Id)
+}
+
+func (r *inviteRepo) GetByCode(ctx context.Context, code string) (*invite.Invite, error) {
+	return r.get(ctx, "repository.postgres.invite.GetByCode", sq.Eq{invitesCodeColumn: code}, true)
+}
+
+func (r *inviteRepo) Get(ctx context.Context, id int64) (*invite.Invite, error) {
+	return r.get(ctx, "repository.postgres.invite.Get", sq.Eq{idColumn: id}, false)
+}
+
+func (r *inviteRepo) get(ctx context.Context, name string, where sq.Eq, forUpdate bool) (*invite.Invite, error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	query := psql.Select(invitesColumns...).
+		From("chat.invites").
+		Where(where)
+	if forUpdate {
+		query = query.Suffix("FOR UPDATE")
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.DB().Query(ctx, db.Query{Name: name, QueryRaw: sql}, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dto, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.InviteDTO])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrInviteNotFound
+		}
+		return nil, err
+	}
+
+	inv := &invite.Invite{
+		Id:        dto.Id,
+		Code:      dto.Code,
+		ChatId:    dto.ChatId,
+		CreatedBy: dto.CreatedBy,
+		Role:      chat.Role(dto.Role),
+		MaxUses:   dto.MaxUses,
+		Uses:      dto.Uses,
+		CreatedAt: dto.CreatedAt,
+	}
+	if dto.ExpiresAt != nil {
+		inv.ExpiresAt = *dto.ExpiresAt
+	}
+	if dto.RevokedAt != nil {
+		inv.RevokedAt = *dto.RevokedAt
+	}
+
+	return inv, nil
+}
+
+func (r *inviteRepo) Revoke(ctx context.Context, inv *invite.Invite) error {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Update("chat.invites").
+		Set(invitesRevokedAtColumn, inv.RevokedAt).
+		Where(sq.Eq{idColumn: inv.Id, invitesRevokedAtColumn: nil}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.conn.DB().Exec(ctx, db.Query{Name: "repository.postgres.invite.Revoke", QueryRaw: sql}, args...)
+
+	return err
+}
+
+func (r *inviteRepo) Use(ctx context.Context, inv *invite.Invite, userId int64) error {
+	return r.conn.DB().ReadCommitted(ctx, func(ctx context.Context) error {
+		psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+		sql, args, err := psql.Update("chat.invites").
+			Set(invitesUsesColumn, inv.Uses).
+			Where(sq.Eq{idColumn: inv.Id}).
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		_, err = r.conn.DB().Exec(ctx, db.Query{Name: "repository.postgres.invite.Use", QueryRaw: sql}, args...)
+		if err != nil {
+			return err
+		}
+
+		sql, args, err = psql.Insert("chat.invite_uses").
+			Columns(inviteUsesInviteIdColumn, inviteUsesUserIdColumn, inviteUsesUsedAtColumn).
+			Values(inv.Id, userId, time.Now()).
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		_, err = r.conn.DB().Exec(ctx, db.Query{Name: "repository.postgres.invite.Use/invite_uses", QueryRaw: sql}, args...)
+
+		return err
+	})
+}
+
+// nullTime нулевое время хранится как NULL
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
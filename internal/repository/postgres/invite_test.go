@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rkchv/chat/internal/domain/invite"
+	"github.com/rkchv/chat/internal/repository"
+)
+
+func TestInviteGetLocksOnlyByCode(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		get    func(r repository.InviteRepository) error
+		locked bool
+	}{
+		{name: "по коду при входе", query: "repository.postgres.invite.GetByCode", locked: true, get: func(r repository.InviteRepository) error {
+			_, err := r.GetByCode(context.Background(), "code")
+			return err
+		}},
+		{name: "по id", query: "repository.postgres.invite.Get", get: func(r repository.InviteRepository) error {
+			_, err := r.Get(context.Background(), 1)
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, fake := newFakeClient()
+			r := NewInviteRepository(client)
+
+			if err := tt.get(r); !errors.Is(err, repository.ErrInviteNotFound) {
+				t.Fatalf("err = %v, want %v", err, repository.ErrInviteNotFound)
+			}
+
+			q, ok := fake.query(tt.query)
+			if !ok {
+				t.Fatalf("query %s was not executed", tt.query)
+			}
+			// без блокировки параллельные входы прочитали бы один и тот же счетчик и превысили лимит
+			if strings.HasSuffix(q.sql, "FOR UPDATE") != tt.locked {
+				t.Fatalf("query %q, want locked = %v", q.sql, tt.locked)
+			}
+		})
+	}
+}
+
+func TestInviteUseSavesCounter(t *testing.T) {
+	client, fake := newFakeClient()
+	r := NewInviteRepository(client)
+
+	if err := r.Use(context.Background(), &invite.Invite{Id: 7, MaxUses: 5, Uses: 3}, 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	q, ok := fake.query("repository.postgres.invite.Use")
+	if !ok {
+		t.Fatal("counter was not saved")
+	}
+	if q.sql != "UPDATE chat.invites SET uses = $1 WHERE id = $2" || q.args[0] != 3 || q.args[1] != int64(7) {
+		t.Fatalf("query %q with %v, want uses = 3 for invite 7", q.sql, q.args)
+	}
+
+	q, ok = fake.query("repository.postgres.invite.Use/invite_uses")
+	if !ok {
+		t.Fatal("use was not recorded")
+	}
+	if q.args[0] != int64(7) || q.args[1] != int64(10) {
+		t.Fatalf("recorded use %v, want invite 7 by user 10", q.args)
+	}
+}
@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/rkchv/chat/lib/db"
+
+	"github.com/rkchv/chat/internal/domain/message"
+	"github.com/rkchv/chat/internal/repository"
+	"github.com/rkchv/chat/internal/repository/postgres/model"
+)
+
+const (
+	mentionsUserIdColumn    = "user_id"
+	mentionsMessageIdColumn = "message_id"
+	mentionsChatIdColumn    = "chat_id"
+	mentionsReadAtColumn    = "read_at"
+)
+
+var _ repository.MentionRepository = (*mentionRepo)(nil)
+
+type mentionRepo struct {
+	conn db.Client
+}
+
+func NewMentionRepository(conn db.Client) repository.MentionRepository {
+	return &mentionRepo{conn: conn}
+}
+
+func (r *mentionRepo) Add(ctx context.Context, msg *message.Message, userIds []int64) ([]int64, error) {
+	if len(userIds) == 0 {
+		return nil, nil
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	insert := psql.Insert("chat.mentions").
+		Columns(mentionsUserIdColumn, mentionsMessageIdColumn, mentionsChatIdColumn, createdColumn)
+	for _, userId := range userIds {
+		insert = insert.Values(userId, msg.Id, msg.ChatId, msg.CreatedAt)
+	}
+
+	sql, args, err := insert.Suffix("ON CONFLICT DO NOTHING RETURNING " + mentionsUserIdColumn).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.DB().Query(ctx, db.Query{Name: "repository.postgres.mention.Add", QueryRaw: sql}, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, pgx.RowTo[int64])
+}
+
+func (r *mentionRepo) List(ctx context.Context, filter repository.MentionFilter) ([]*message.MentionItem, error) {
+	columns := make([]string, 0, len(messageColumns)+1)
+	for _, column := range messageColumns {
+		columns = append(columns, "m."+column)
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	query := psql.Select(columns...).
+		Column("mn.read_at").
+		From("chat.mentions mn").
+		Join("chat.messages m ON m.id = mn.message_id").
+		// из чатов, которые пользователь покинул, упоминания не показываем
+		Join("chat.chat_users cu ON cu.chat_id = mn.chat_id AND cu.user_id = mn.user_id").
+		Where(sq.Eq{"mn.user_id": filter.UserId, "m.deleted_at": nil}).
+		OrderBy("mn.created_at DESC", "mn.message_id DESC").
+		Limit(filter.Limit)
+
+	if filter.UnreadOnly {
+		query = query.Where(sq.Eq{"mn.read_at": nil})
+	}
+
+	if filter.After != nil {
+		query = query.Where(sq.Expr("(mn.created_at, mn.message_id) < (?, ?)", filter.After.CreatedAt, filter.After.Id))
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.DB().Query(ctx, db.Query{Name: "repository.postgres.mention.List", QueryRaw: sql}, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dtos, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.MentionItemDTO])
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*message.MentionItem, 0, len(dtos))
+	messages := make([]*message.Message, 0, len(dtos))
+	for _, dto := range dtos {
+		item := &message.MentionItem{Message: toDomainMessage(dto.MessageDTO)}
+		if dto.ReadAt != nil {
+			item.ReadAt = *dto.ReadAt
+		}
+		items = append(items, item)
+		messages = append(messages, item.Message)
+	}
+
+	if err = loadAttachments(ctx, r.conn, messages); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *mentionRepo) Unread(ctx context.Context, userId int64) (int64, error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Select("count(*)").
+		From("chat.mentions mn").
+		Join("chat.chat_users cu ON cu.chat_id = mn.chat_id AND cu.user_id = mn.user_id").
+		Join("chat.messages m ON m.id = mn.message_id").
+		Where(sq.Eq{"mn.user_id": userId, "mn.read_at": nil, "m.deleted_at": nil}).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = r.conn.DB().QueryRow(ctx, db.Query{Name: "repository.postgres.mention.Unread", QueryRaw: sql}, args...).Scan(&count)
+
+	return count, err
+}
+
+func (r *mentionRepo) MarkRead(ctx context.Context, userId int64, chatId int64, messageId int64) error {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Update("chat.mentions").
+		Set(mentionsReadAtColumn, sq.Expr("now()")).
+		Where(sq.Eq{mentionsUserIdColumn: userId, mentionsChatIdColumn: chatId, mentionsReadAtColumn: nil}).
+		Where(sq.LtOrEq{mentionsMessageIdColumn: messageId}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.conn.DB().Exec(ctx, db.Query{Name: "repository.postgres.mention.MarkRead", QueryRaw: sql}, args...)
+
+	return err
+}
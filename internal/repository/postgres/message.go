@@ -0,0 +1,350 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/rkchv/chat/lib/db"
+
+	"github.com/rkchv/chat/internal/domain/message"
+	"github.com/rkchv/chat/internal/repository"
+	"github.com/rkchv/chat/internal/repository/postgres/model"
+)
+
+const (
+	messagesChatIdColumn       = "chat_id"
+	messagesUserIdColumn       = "user_id"
+	messagesTextColumn         = "text"
+	messagesUpdatedAtColumn    = "updated_at"
+	messagesDeletedAtColumn    = "deleted_at"
+	messagesSearchConfigColumn = "search_config"
+	messagesMentionsColumn     = "mentions"
+
+	revisionsMessageIdColumn = "message_id"
+	revisionsActionColumn    = "action"
+	revisionsTextColumn      = "text"
+	revisionsChangedByColumn = "changed_by"
+)
+
+var messageColumns = []string{
+	idColumn,
+	messagesChatIdColumn,
+	messagesUserIdColumn,
+	messagesTextColumn,
+	createdColumn,
+	messagesUpdatedAtColumn,
+	messagesDeletedAtColumn,
+	messagesMentionsColumn,
+}
+
+var _ repository.MessageRepository = (*messageRepo)(nil)
+
+// headlineOptions как ts_headline вырезает фрагменты текста и выделяет совпадения
+var headlineOptions = fmt.Sprintf(`StartSel="%s", StopSel="%s", MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "`,
+	message.HighlightStart, message.HighlightStop)
+
+type messageRepo struct {
+	conn db.Client
+	// searchConfig конфигурация текстового поиска, с которой индексируются новые сообщения и разбираются запросы
+	searchConfig string
+}
+
+func NewMessageRepository(conn db.Client, searchConfig string) repository.MessageRepository {
+	return &messageRepo{conn: conn, searchConfig: searchConfig}
+}
+
+func (r *messageRepo) Save(ctx context.Context, msg *message.Message) error {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Insert("chat.messages").
+		Columns(messagesChatIdColumn, messagesUserIdColumn, messagesTextColumn, createdColumn, messagesSearchConfigColumn, messagesMentionsColumn).
+		Values(msg.ChatId, msg.UserId, msg.Text, msg.CreatedAt, sq.Expr("?::regconfig", r.searchConfig), toMentionDTOs(msg.Mentions)).
+		Suffix(fmt.Sprintf("RETURNING %s", idColumn)).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	q := db.Query{Name: "repository.postgres.messages.Save", QueryRaw: sql}
+
+	return r.conn.DB().QueryRow(ctx, q, args...).Scan(&msg.Id)
+}
+
+// History возвращает страницу сообщений чата после заданного сообщения/момента в хронологическом порядке
+func (r *messageRepo) History(ctx context.Context, filter repository.HistoryFilter) ([]*message.Message, error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	query := psql.Select(messageColumns...).
+		From("chat.messages").
+		Where(sq.Eq{messagesChatIdColumn: filter.ChatId, messagesDeletedAtColumn: nil}).
+		OrderBy(createdColumn+" ASC", idColumn+" ASC").
+		Limit(filter.Limit)
+
+	if filter.SinceId > 0 {
+		query = query.Where(sq.Gt{idColumn: filter.SinceId})
+	}
+
+	if !filter.Since.IsZero() {
+		query = query.Where(sq.Gt{createdColumn: filter.Since})
+	}
+
+	if filter.After != nil {
+		query = query.Where(sq.Expr(fmt.Sprintf("(%s, %s) > (?, ?)", createdColumn, idColumn), filter.After.CreatedAt, filter.After.Id))
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.DB().Query(ctx, db.Query{Name: "repository.postgres.messages.History", QueryRaw: sql}, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dtos, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.MessageDTO])
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]*message.Message, 0, len(dtos))
+	for _, dto := range dtos {
+		messages = append(messages, toDomainMessage(dto))
+	}
+
+	if err = loadAttachments(ctx, r.conn, messages); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// List возвращает страницу сообщений чата, сортировка и сравнение с курсором по паре (created_at, id)
+func (r *messageRepo) List(ctx context.Context, filter repository.MessageFilter) ([]*message.Message, error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	query := psql.Select(messageColumns...).
+		From("chat.messages").
+		Where(sq.Eq{messagesChatIdColumn: filter.ChatId, messagesDeletedAtColumn: nil}).
+		Limit(filter.Limit)
+
+	keyset := fmt.Sprintf("(%s, %s)", createdColumn, idColumn)
+	if filter.Newer {
+		query = query.OrderBy(createdColumn+" ASC", idColumn+" ASC")
+		if filter.After != nil {
+			query = query.Where(sq.Expr(keyset+" > (?, ?)", filter.After.CreatedAt, filter.After.Id))
+		}
+	} else {
+		query = query.OrderBy(createdColumn+" DESC", idColumn+" DESC")
+		if filter.After != nil {
+			query = query.Where(sq.Expr(keyset+" < (?, ?)", filter.After.CreatedAt, filter.After.Id))
+		}
+	}
+
+	if filter.SenderId > 0 {
+		query = query.Where(sq.Eq{messagesUserIdColumn: filter.SenderId})
+	}
+
+	if !filter.From.IsZero() {
+		query = query.Where(sq.GtOrEq{createdColumn: filter.From})
+	}
+
+	if !filter.To.IsZero() {
+		query = query.Where(sq.Lt{createdColumn: filter.To})
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.DB().Query(ctx, db.Query{Name: "repository.postgres.messages.List", QueryRaw: sql}, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dtos, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.MessageDTO])
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]*message.Message, 0, len(dtos))
+	for _, dto := range dtos {
+		messages = append(messages, toDomainMessage(dto))
+	}
+
+	if err = loadAttachments(ctx, r.conn, messages); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// Search ищет сообщения по tsvector (GIN индекс) только в чатах, где пользователь сейчас участник.
+// Запрос разбирается websearch_to_tsquery, так что работают фразы в кавычках, or и исключение через минус
+func (r *messageRepo) Search(ctx context.Context, filter repository.SearchFilter) ([]*message.SearchHit, error) {
+	columns := make([]string, 0, len(messageColumns)+1)
+	for _, column := range messageColumns {
+		columns = append(columns, "m."+column)
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	query := psql.Select(columns...).
+		Column("ts_headline(m.search_config, m.text, q, ?) AS snippet", headlineOptions).
+		From("chat.messages m").
+		Join("chat.chat_users cu ON cu.chat_id = m.chat_id AND cu.user_id = ?", filter.UserId).
+		JoinClause("CROSS JOIN websearch_to_tsquery(?::regconfig, ?) q", r.searchConfig, filter.Query).
+		Where("m.search_vector @@ q").
+		Where(sq.Eq{"m." + messagesDeletedAtColumn: nil}).
+		OrderBy("m."+createdColumn+" DESC", "m."+idColumn+" DESC").
+		Limit(filter.Limit)
+
+	if filter.ChatId > 0 {
+		query = query.Where(sq.Eq{"m." + messagesChatIdColumn: filter.ChatId})
+	}
+
+	if filter.After != nil {
+		query = query.Where(sq.Expr("(m.created_at, m.id) < (?, ?)", filter.After.CreatedAt, filter.After.Id))
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.DB().Query(ctx, db.Query{Name: "repository.postgres.messages.Search", QueryRaw: sql}, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dtos, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.SearchHitDTO])
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]*message.SearchHit, 0, len(dtos))
+	messages := make([]*message.Message, 0, len(dtos))
+	for _, dto := range dtos {
+		msg := toDomainMessage(dto.MessageDTO)
+		hits = append(hits, &message.SearchHit{Message: msg, Snippet: dto.Snippet})
+		messages = append(messages, msg)
+	}
+
+	if err = loadAttachments(ctx, r.conn, messages); err != nil {
+		return nil, err
+	}
+
+	return hits, nil
+}
+
+// Get возвращает сообщение по id, в том числе удаленное
+func (r *messageRepo) Get(ctx context.Context, id int64) (*message.Message, error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Select(messageColumns...).
+		From("chat.messages").
+		Where(sq.Eq{idColumn: id}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.DB().Query(ctx, db.Query{Name: "repository.postgres.messages.Get", QueryRaw: sql}, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dto, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.MessageDTO])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrMessageNotFound
+		}
+		return nil, err
+	}
+
+	msg := toDomainMessage(dto)
+	if err = loadAttachments(ctx, r.conn, []*message.Message{msg}); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// Update сохраняет изменения сообщения вместе с ревизией его прежнего состояния.
+// Удаленное сообщение не меняется: правка, которая гонится с удалением, вернет ErrMessageNotFound, а не текст
+func (r *messageRepo) Update(ctx context.Context, msg *message.Message, rev message.Revision) error {
+	err := r.conn.DB().ReadCommitted(ctx, func(ctx context.Context) error {
+		psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+		update := psql.Update("chat.messages").
+			Set(messagesTextColumn, msg.Text).
+			Set(messagesMentionsColumn, toMentionDTOs(msg.Mentions)).
+			Set(messagesUpdatedAtColumn, msg.UpdatedAt).
+			Where(sq.Eq{idColumn: msg.Id, messagesDeletedAtColumn: nil})
+		if msg.IsDeleted() {
+			update = update.Set(messagesDeletedAtColumn, msg.DeletedAt)
+		}
+
+		sql, args, err := update.ToSql()
+		if err != nil {
+			return err
+		}
+
+		q := db.Query{Name: "repository.postgres.messages.Update/messages", QueryRaw: sql}
+		tag, err := r.conn.DB().Exec(ctx, q, args...)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return repository.ErrMessageNotFound
+		}
+
+		sql, args, err = psql.Insert("chat.message_revisions").
+			Columns(revisionsMessageIdColumn, revisionsActionColumn, revisionsTextColumn, revisionsChangedByColumn, createdColumn).
+			Values(rev.MessageId, string(rev.Action), rev.Text, rev.ChangedBy, rev.CreatedAt).
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		q = db.Query{Name: "repository.postgres.messages.Update/revisions", QueryRaw: sql}
+		_, err = r.conn.DB().Exec(ctx, q, args...)
+
+		return err
+	})
+
+	return err
+}
+
+func toDomainMessage(dto model.MessageDTO) *message.Message {
+	msg := &message.Message{
+		Id:        dto.Id,
+		ChatId:    dto.ChatId,
+		UserId:    dto.UserId,
+		Text:      dto.Text,
+		CreatedAt: dto.CreatedAt,
+	}
+	if dto.UpdatedAt != nil {
+		msg.UpdatedAt = *dto.UpdatedAt
+	}
+	if dto.DeletedAt != nil {
+		msg.DeletedAt = *dto.DeletedAt
+	}
+	for _, m := range dto.Mentions {
+		msg.Mentions = append(msg.Mentions, message.Mention{UserId: m.UserId, All: m.All, Offset: m.Offset, Length: m.Length})
+	}
+
+	return msg
+}
+
+// toMentionDTOs упоминания для записи в jsonb, пустой список пишется как [], а не null
+func toMentionDTOs(mentions []message.Mention) []model.MentionDTO {
+	dtos := make([]model.MentionDTO, 0, len(mentions))
+	for _, m := range mentions {
+		dtos = append(dtos, model.MentionDTO{UserId: m.UserId, All: m.All, Offset: m.Offset, Length: m.Length})
+	}
+
+	return dtos
+}
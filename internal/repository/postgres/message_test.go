@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rkchv/chat/internal/repository"
+)
+
+func TestMessageListKeyset(t *testing.T) {
+	after := &repository.MessageKey{CreatedAt: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC), Id: 42}
+
+	tests := []struct {
+		name   string
+		filter repository.MessageFilter
+		where  string
+		order  string
+		args   []interface{}
+	}{
+		{
+			name:   "первая страница от новых к старым",
+			filter: repository.MessageFilter{ChatId: 1, Limit: 10},
+			order:  "ORDER BY created_at DESC, id DESC",
+			args:   []interface{}{int64(1)},
+		},
+		{
+			name:   "следующая страница от новых к старым",
+			filter: repository.MessageFilter{ChatId: 1, After: after, Limit: 10},
+			where:  "(created_at, id) < ($2, $3)",
+			order:  "ORDER BY created_at DESC, id DESC",
+			args:   []interface{}{int64(1), after.CreatedAt, after.Id},
+		},
+		{
+			name:   "следующая страница от старых к новым",
+			filter: repository.MessageFilter{ChatId: 1, After: after, Newer: true, Limit: 10},
+			where:  "(created_at, id) > ($2, $3)",
+			order:  "ORDER BY created_at ASC, id ASC",
+			args:   []interface{}{int64(1), after.CreatedAt, after.Id},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, fake := newFakeClient()
+			r := NewMessageRepository(client, "simple")
+
+			if _, err := r.List(context.Background(), tt.filter); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			q, ok := fake.query("repository.postgres.messages.List")
+			if !ok {
+				t.Fatal("list query was not executed")
+			}
+			if !strings.Contains(q.sql, "deleted_at IS NULL") {
+				t.Errorf("deleted messages are not filtered out: %s", q.sql)
+			}
+			if tt.where != "" && !strings.Contains(q.sql, tt.where) {
+				t.Errorf("query %q has no keyset condition %q", q.sql, tt.where)
+			}
+			if tt.where == "" && strings.Contains(q.sql, "(created_at, id)") {
+				t.Errorf("first page must not have a keyset condition: %s", q.sql)
+			}
+			if !strings.Contains(q.sql, tt.order) {
+				t.Errorf("query %q is not ordered by %q", q.sql, tt.order)
+			}
+			if !strings.HasSuffix(q.sql, "LIMIT 10") {
+				t.Errorf("query %q is not limited", q.sql)
+			}
+			if len(q.args) != len(tt.args) {
+				t.Fatalf("args = %v, want %v", q.args, tt.args)
+			}
+			for i := range tt.args {
+				if q.args[i] != tt.args[i] {
+					t.Errorf("arg %d = %v, want %v", i, q.args[i], tt.args[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+)
+
+type AttachmentDTO struct {
+	Id         int64     `db:"id"`
+	ChatId     int64     `db:"chat_id"`
+	MessageId  *int64    `db:"message_id"`
+	UploadedBy int64     `db:"uploaded_by"`
+	BlobKey    string    `db:"blob_key"`
+	FileName   string    `db:"file_name"`
+	MimeType   string    `db:"mime_type"`
+	Size       int64     `db:"size"`
+	CreatedAt  time.Time `db:"created_at"`
+}
@@ -5,7 +5,39 @@ import (
 )
 
 type ChatDTO struct {
-	Id        int32
-	UserIds   []int64
-	CreatedAt time.Time
+	Id          int64     `db:"id"`
+	Title       string    `db:"title"`
+	Description string    `db:"description"`
+	Type        string    `db:"type"`
+	CreatedBy   *int64    `db:"created_by"`
+	PinnedId    *int64    `db:"pinned_message_id"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+type MemberDTO struct {
+	UserId     int64      `db:"user_id"`
+	UserName   string     `db:"user_name"`
+	Role       string     `db:"role"`
+	Muted      bool       `db:"muted"`
+	MutedUntil *time.Time `db:"muted_until"`
+}
+
+type ChatSummaryDTO struct {
+	Id                int64      `db:"id"`
+	Title             string     `db:"title"`
+	Type              string     `db:"type"`
+	MemberCount       int        `db:"member_count"`
+	UnreadCount       int        `db:"unread_count"`
+	ActivityAt        time.Time  `db:"activity_at"`
+	LastMessageId     *int64     `db:"last_message_id"`
+	LastMessageUserId *int64     `db:"last_message_user_id"`
+	LastMessageText   *string    `db:"last_message_text"`
+	LastMessageAt     *time.Time `db:"last_message_at"`
+}
+
+type ReadPositionDTO struct {
+	UserId    int64      `db:"user_id"`
+	UserName  string     `db:"user_name"`
+	MessageId int64      `db:"last_read_message_id"`
+	ReadAt    *time.Time `db:"last_read_at"`
 }
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+)
+
+type InviteDTO struct {
+	Id        int64      `db:"id"`
+	Code      string     `db:"code"`
+	ChatId    int64      `db:"chat_id"`
+	CreatedBy int64      `db:"created_by"`
+	Role      string     `db:"role"`
+	MaxUses   int        `db:"max_uses"`
+	Uses      int        `db:"uses"`
+	ExpiresAt *time.Time `db:"expires_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
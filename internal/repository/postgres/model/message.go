@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+)
+
+type MessageDTO struct {
+	Id        int64      `db:"id"`
+	ChatId    int64      `db:"chat_id"`
+	UserId    int64      `db:"user_id"`
+	Text      string     `db:"text"`
+	CreatedAt time.Time  `db:"created_at"`
+	UpdatedAt *time.Time `db:"updated_at"`
+	DeletedAt *time.Time `db:"deleted_at"`
+	// Mentions хранятся в jsonb вместе с сообщением
+	Mentions []MentionDTO `db:"mentions"`
+}
+
+type MentionDTO struct {
+	UserId int64 `json:"user_id,omitempty"`
+	All    bool  `json:"all,omitempty"`
+	Offset int   `json:"offset"`
+	Length int   `json:"length"`
+}
+
+type MentionItemDTO struct {
+	MessageDTO
+	ReadAt *time.Time `db:"read_at"`
+}
+
+type SearchHitDTO struct {
+	MessageDTO
+	Snippet string `db:"snippet"`
+}
@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+)
+
+type OutboxDTO struct {
+	Id        int64     `db:"id"`
+	Topic     string    `db:"topic"`
+	Key       string    `db:"key"`
+	Type      string    `db:"type"`
+	Payload   []byte    `db:"payload"`
+	CreatedAt time.Time `db:"created_at"`
+	Seq       int64     `db:"seq"`
+}
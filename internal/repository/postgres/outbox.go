@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/rkchv/chat/lib/db"
+
+	"github.com/rkchv/chat/internal/domain/outbox"
+	"github.com/rkchv/chat/internal/repository"
+	"github.com/rkchv/chat/internal/repository/postgres/model"
+)
+
+const (
+	outboxTopicColumn       = "topic"
+	outboxKeyColumn         = "key"
+	outboxTypeColumn        = "type"
+	outboxPayloadColumn     = "payload"
+	outboxPublishedAtColumn = "published_at"
+	outboxSeqColumn         = "seq"
+
+	sequencesKeyColumn = "key"
+	sequencesSeqColumn = "seq"
+)
+
+var _ repository.OutboxRepository = (*outboxRepo)(nil)
+
+type outboxRepo struct {
+	conn db.Client
+}
+
+func NewOutboxRepository(conn db.Client) repository.OutboxRepository {
+	return &outboxRepo{conn: conn}
+}
+
+// Add добавляет событие со следующим номером по его ключу. Счетчик ключа остается заблокированным до конца транзакции,
+// поэтому транзакции с событиями одного чата фиксируются по очереди, в порядке номеров и id событий
+func (r *outboxRepo) Add(ctx context.Context, event *outbox.Event) error {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Insert("chat.outbox").
+		Prefix("WITH next_seq AS (?)", sq.Insert("chat.outbox_sequences").
+			Columns(sequencesKeyColumn, sequencesSeqColumn).
+			Values(event.Key, 1).
+			Suffix(fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s = chat.outbox_sequences.%s + 1 RETURNING %s",
+				sequencesKeyColumn, sequencesSeqColumn, sequencesSeqColumn, sequencesSeqColumn))).
+		Columns(outboxTopicColumn, outboxKeyColumn, outboxTypeColumn, outboxPayloadColumn, createdColumn, outboxSeqColumn).
+		Values(event.Topic, event.Key, event.Type, string(event.Payload), event.CreatedAt, sq.Expr("(SELECT "+sequencesSeqColumn+" FROM next_seq)")).
+		Suffix("RETURNING " + idColumn + ", " + outboxSeqColumn).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	q := db.Query{Name: "repository.postgres.outbox.Add", QueryRaw: sql}
+
+	return r.conn.DB().QueryRow(ctx, q, args...).Scan(&event.Id, &event.Seq)
+}
+
+// relayLockKey ключ advisory-блокировки, под которой публикует события только один экземпляр
+const relayLockKey = "chat.outbox.relay"
+
+func (r *outboxRepo) TryLock(ctx context.Context) (bool, error) {
+	q := db.Query{Name: "repository.postgres.outbox.TryLock", QueryRaw: "SELECT pg_try_advisory_xact_lock(hashtext($1))"}
+
+	var locked bool
+	err := r.conn.DB().QueryRow(ctx, q, relayLockKey).Scan(&locked)
+
+	return locked, err
+}
+
+// FetchUnpublished выбирает и блокирует до конца транзакции неопубликованные события в порядке id.
+// Для событий одного чата он совпадает с порядком фиксации: следующее событие чата получает id только после того,
+// как зафиксирована транзакция с предыдущим (см. Add). Так что видимые события чата всегда идут без пропусков
+func (r *outboxRepo) FetchUnpublished(ctx context.Context, limit uint64) ([]*outbox.Event, error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Select(idColumn, outboxTopicColumn, outboxKeyColumn, outboxTypeColumn, outboxPayloadColumn, createdColumn, outboxSeqColumn).
+		From("chat.outbox").
+		Where(sq.Eq{outboxPublishedAtColumn: nil}).
+		OrderBy(idColumn).
+		Limit(limit).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.DB().Query(ctx, db.Query{Name: "repository.postgres.outbox.FetchUnpublished", QueryRaw: sql}, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dtos, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.OutboxDTO])
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*outbox.Event, 0, len(dtos))
+	for _, dto := range dtos {
+		events = append(events, &outbox.Event{
+			Id:        dto.Id,
+			Topic:     dto.Topic,
+			Key:       dto.Key,
+			Type:      dto.Type,
+			Payload:   dto.Payload,
+			CreatedAt: dto.CreatedAt,
+			Seq:       dto.Seq,
+		})
+	}
+
+	return events, nil
+}
+
+func (r *outboxRepo) MarkPublished(ctx context.Context, ids []int64) error {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Update("chat.outbox").
+		Set(outboxPublishedAtColumn, sq.Expr("now()")).
+		Where(sq.Eq{idColumn: ids}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.conn.DB().Exec(ctx, db.Query{Name: "repository.postgres.outbox.MarkPublished", QueryRaw: sql}, args...)
+
+	return err
+}
+
+// DeletePublished удаляет не больше limit событий, опубликованных раньше before, возвращает сколько удалено
+func (r *outboxRepo) DeletePublished(ctx context.Context, before time.Time, limit uint64) (int64, error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Delete("chat.outbox").
+		Where(sq.Expr(idColumn+" IN (?)", sq.Select(idColumn).
+			From("chat.outbox").
+			Where(sq.Lt{outboxPublishedAtColumn: before}).
+			Limit(limit))).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := r.conn.DB().Exec(ctx, db.Query{Name: "repository.postgres.outbox.DeletePublished", QueryRaw: sql}, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rkchv/chat/internal/domain/outbox"
+)
+
+func TestOutboxQueries(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		run   func(r *outboxRepo) error
+		want  []string
+	}{
+		{
+			name:  "номер события берется из счетчика чата, который блокируется до конца транзакции",
+			query: "repository.postgres.outbox.Add",
+			run: func(r *outboxRepo) error {
+				return r.Add(context.Background(), &outbox.Event{Topic: outbox.TopicMessages, Key: "5", Type: outbox.MessageCreated})
+			},
+			want: []string{
+				"WITH next_seq AS (INSERT INTO chat.outbox_sequences (key,seq) VALUES ($1,$2)",
+				"ON CONFLICT (key) DO UPDATE SET seq = chat.outbox_sequences.seq + 1 RETURNING seq)",
+				"(SELECT seq FROM next_seq)",
+				"RETURNING id, seq",
+			},
+		},
+		{
+			name:  "неопубликованные выбираются по порядку id и блокируются",
+			query: "repository.postgres.outbox.FetchUnpublished",
+			run: func(r *outboxRepo) error {
+				_, err := r.FetchUnpublished(context.Background(), 100)
+				return err
+			},
+			want: []string{"WHERE published_at IS NULL", "ORDER BY id", "LIMIT 100 FOR UPDATE"},
+		},
+		{
+			name:  "удаляются только опубликованные раньше срока и пачкой",
+			query: "repository.postgres.outbox.DeletePublished",
+			run: func(r *outboxRepo) error {
+				_, err := r.DeletePublished(context.Background(), time.Now(), 1000)
+				return err
+			},
+			want: []string{"DELETE FROM chat.outbox WHERE id IN (SELECT id FROM chat.outbox WHERE published_at < $1 LIMIT 1000)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, fake := newFakeClient()
+			fake.rows["repository.postgres.outbox.Add"] = []interface{}{int64(10), int64(3)}
+			r := &outboxRepo{conn: client}
+
+			if err := tt.run(r); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			q, ok := fake.query(tt.query)
+			if !ok {
+				t.Fatalf("query %s was not executed", tt.query)
+			}
+			for _, part := range tt.want {
+				if !strings.Contains(q.sql, part) {
+					t.Errorf("query %q does not contain %q", q.sql, part)
+				}
+			}
+		})
+	}
+}
+
+func TestOutboxAddReturnsSeq(t *testing.T) {
+	client, fake := newFakeClient()
+	fake.rows["repository.postgres.outbox.Add"] = []interface{}{int64(10), int64(3)}
+	r := &outboxRepo{conn: client}
+
+	event := &outbox.Event{Topic: outbox.TopicMessages, Key: "5", Type: outbox.MessageCreated}
+	if err := r.Add(context.Background(), event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event.Id != 10 || event.Seq != 3 {
+		t.Fatalf("event id = %d, seq = %d, want 10 and 3", event.Id, event.Seq)
+	}
+
+	q, _ := fake.query("repository.postgres.outbox.Add")
+	// счетчик и событие относятся к одному ключу
+	if q.args[0] != "5" || q.args[3] != "5" {
+		t.Fatalf("args = %v, want key 5 for both the sequence and the event", q.args)
+	}
+}
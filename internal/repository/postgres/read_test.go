@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	domain "github.com/rkchv/chat/internal/domain/chat"
+)
+
+func TestMarkReadOnlyMovesForward(t *testing.T) {
+	tests := []struct {
+		name         string
+		rowsAffected int64
+		moved        bool
+	}{
+		{name: "позиция сдвинулась", rowsAffected: 1, moved: true},
+		{name: "позиция уже дальше", rowsAffected: 0, moved: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, fake := newFakeClient()
+			fake.rowsAffected = tt.rowsAffected
+			r := New(client)
+
+			moved, err := r.MarkRead(context.Background(), 1, domain.ReadPosition{UserId: 10, MessageId: 42, ReadAt: time.Now()})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if moved != tt.moved {
+				t.Fatalf("moved = %v, want %v", moved, tt.moved)
+			}
+
+			q, ok := fake.query("repository.postgres.MarkRead")
+			if !ok {
+				t.Fatal("mark read query was not executed")
+			}
+			// позиция сравнивается в том же запросе, иначе запоздавший запрос откатил бы ее назад
+			if !strings.Contains(q.sql, "last_read_message_id < $5") || q.args[4] != int64(42) {
+				t.Fatalf("query %q with %v does not guard against moving back", q.sql, q.args)
+			}
+		})
+	}
+}
@@ -4,20 +4,41 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
 	"github.com/rkchv/chat/lib/db"
 
 	domain "github.com/rkchv/chat/internal/domain/chat"
+	"github.com/rkchv/chat/internal/domain/message"
 	"github.com/rkchv/chat/internal/repository"
+	"github.com/rkchv/chat/internal/repository/postgres/model"
 )
 
 const (
-	createdColumn     = "created_at"
-	idColumn          = "id"
-	usersChatIdColumn = "chat_id"
-	usersUserIdColumn = "user_id"
+	createdColumn         = "created_at"
+	idColumn              = "id"
+	titleColumn           = "title"
+	descriptionColumn     = "description"
+	typeColumn            = "type"
+	createdByColumn       = "created_by"
+	pinnedColumn          = "pinned_message_id"
+	usersChatIdColumn     = "chat_id"
+	usersUserIdColumn     = "user_id"
+	usersUserNameColumn   = "user_name"
+	usersRoleColumn       = "role"
+	usersMutedColumn      = "muted"
+	usersMutedUntilColumn = "muted_until"
+	usersLastReadColumn   = "last_read_message_id"
+	usersLastReadAtColumn = "last_read_at"
+	usersJoinedAtColumn   = "joined_at"
+
+	bansChatIdColumn   = "chat_id"
+	bansUserIdColumn   = "user_id"
+	bansBannedByColumn = "banned_by"
+	bansReasonColumn   = "reason"
+	bansBannedAtColumn = "banned_at"
 )
 
 var _ repository.Repository = (*repo)(nil)
@@ -33,23 +54,28 @@ func New(conn db.Client) repository.Repository {
 }
 
 func (r *repo) Save(ctx context.Context, chat *domain.Chat) error {
-	insert, _, err := sq.Insert("chat.chats").
-		Columns(createdColumn).
-		Values(sq.Expr("now()")).
-		Suffix(fmt.Sprintf("RETURNING %s", idColumn)).
-		ToSql()
-	if err != nil {
-		return err
-	}
+	err := r.conn.DB().ReadCommitted(ctx, func(ctx context.Context) error {
+		psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+		insert, args, err := psql.Insert("chat.chats").
+			Columns(titleColumn, descriptionColumn, typeColumn, createdByColumn, createdColumn).
+			Values(chat.Title, chat.Description, string(chat.Type), chat.CreatedBy, chat.CreatedAt).
+			Suffix(fmt.Sprintf("RETURNING %s", idColumn)).
+			ToSql()
+		if err != nil {
+			return err
+		}
 
-	q := db.Query{Name: "repository.postgres.Save", QueryRaw: insert}
+		q := db.Query{Name: "repository.postgres.Save", QueryRaw: insert}
 
-	err = r.conn.DB().QueryRow(ctx, q).Scan(&chat.Id)
-	if err != nil {
-		return err
-	}
+		err = r.conn.DB().QueryRow(ctx, q, args...).Scan(&chat.Id)
+		if err != nil {
+			return err
+		}
+
+		return r.insertMembers(ctx, chat.Id, chat.Members)
+	})
 
-	return nil
+	return err
 }
 
 func (r *repo) Delete(ctx context.Context, id int64) error {
@@ -68,6 +94,32 @@ func (r *repo) Delete(ctx context.Context, id int64) error {
 			return err
 		}
 
+		sql, args, err = psql.Delete("chat.message_revisions").
+			Where(sq.Expr(revisionsMessageIdColumn+" IN (SELECT id FROM chat.messages WHERE chat_id = ?)", id)).
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		q = db.Query{Name: "repository.postgres.Delete/message_revisions", QueryRaw: sql}
+		_, err = r.conn.DB().Exec(ctx, q, args...)
+		if err != nil {
+			return err
+		}
+
+		sql, args, err = psql.Delete("chat.messages").
+			Where(sq.Eq{messagesChatIdColumn: id}).
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		q = db.Query{Name: "repository.postgres.Delete/messages", QueryRaw: sql}
+		_, err = r.conn.DB().Exec(ctx, q, args...)
+		if err != nil {
+			return err
+		}
+
 		sql, args, err = psql.Delete("chat.chats").
 			Where(sq.Eq{idColumn: id}).
 			ToSql()
@@ -85,11 +137,31 @@ func (r *repo) Delete(ctx context.Context, id int64) error {
 }
 
 func (r *repo) Get(ctx context.Context, chatId int64) (*domain.Chat, error) {
+	return r.get(ctx, chatId, false)
+}
+
+// GetForUpdate как Get, но блокирует участников чата до конца транзакции
+func (r *repo) GetForUpdate(ctx context.Context, chatId int64) (*domain.Chat, error) {
+	return r.get(ctx, chatId, true)
+}
+
+func (r *repo) get(ctx context.Context, chatId int64, forUpdate bool) (*domain.Chat, error) {
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-	sql, args, err := psql.Select(idColumn).
+	sql, args, err := psql.Select(idColumn, titleColumn, descriptionColumn, typeColumn, createdByColumn, pinnedColumn, createdColumn).
 		From("chat.chats").
 		Where(sq.Eq{idColumn: chatId}).
 		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.DB().Query(ctx, db.Query{Name: "repository.postgres.Get", QueryRaw: sql}, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dto, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.ChatDTO])
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, repository.ErrChatNotFound
@@ -97,67 +169,447 @@ func (r *repo) Get(ctx context.Context, chatId int64) (*domain.Chat, error) {
 		return nil, err
 	}
 
-	var chat domain.Chat
-	err = r.conn.DB().QueryRow(ctx, db.Query{Name: "repository.postgres.Get", QueryRaw: sql}, args...).Scan(&chat.Id)
+	members := psql.Select(usersUserIdColumn, usersUserNameColumn, usersRoleColumn, usersMutedColumn, usersMutedUntilColumn).
+		From("chat.chat_users").
+		Where(sq.Eq{usersChatIdColumn: chatId})
+	if forUpdate {
+		members = members.Suffix("FOR UPDATE")
+	}
+
+	sql, args, err = members.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = r.conn.DB().Query(ctx, db.Query{Name: "repository.postgres.Get/chat_users", QueryRaw: sql}, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	memberDTOs, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.MemberDTO])
+	if err != nil {
+		return nil, err
+	}
+
+	chat := &domain.Chat{
+		Id:          dto.Id,
+		Title:       dto.Title,
+		Description: dto.Description,
+		Type:        domain.Type(dto.Type),
+		CreatedAt:   dto.CreatedAt,
+		Members:     make([]domain.Member, 0, len(memberDTOs)),
+	}
+	if dto.CreatedBy != nil {
+		chat.CreatedBy = *dto.CreatedBy
+	}
+	if dto.PinnedId != nil {
+		chat.PinnedMessageId = *dto.PinnedId
+	}
+	for _, m := range memberDTOs {
+		member := domain.Member{UserId: m.UserId, UserName: m.UserName, Role: domain.Role(m.Role), Muted: m.Muted}
+		if m.MutedUntil != nil {
+			member.MutedUntil = *m.MutedUntil
+		}
+		chat.Members = append(chat.Members, member)
+	}
+
+	return chat, nil
+}
+
+// insertMembers добавляет участников в чат, уже существующих пропускает
+func (r *repo) insertMembers(ctx context.Context, chatId int64, members []domain.Member) error {
+	if len(members) == 0 {
+		return nil
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	insertQuery := psql.Insert("chat.chat_users").
+		Columns(usersChatIdColumn, usersUserIdColumn, usersUserNameColumn, usersRoleColumn).
+		Suffix("ON CONFLICT DO NOTHING")
+
+	for _, m := range members {
+		insertQuery = insertQuery.Values(chatId, m.UserId, m.UserName, string(m.Role))
+	}
+
+	sql, args, err := insertQuery.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.conn.DB().Exec(ctx, db.Query{Name: "repository.postgres.insertMembers", QueryRaw: sql}, args...)
+
+	return err
+}
+
+func (r *repo) AddMembers(ctx context.Context, chatId int64, members []domain.Member) ([]int64, error) {
+	if len(members) == 0 {
+		return nil, nil
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	insertQuery := psql.Insert("chat.chat_users").
+		Columns(usersChatIdColumn, usersUserIdColumn, usersUserNameColumn, usersRoleColumn).
+		Suffix("ON CONFLICT DO NOTHING RETURNING " + usersUserIdColumn)
+
+	for _, m := range members {
+		insertQuery = insertQuery.Values(chatId, m.UserId, m.UserName, string(m.Role))
+	}
+
+	sql, args, err := insertQuery.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.DB().Query(ctx, db.Query{Name: "repository.postgres.AddMembers", QueryRaw: sql}, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, pgx.RowTo[int64])
+}
+
+func (r *repo) RemoveMember(ctx context.Context, chatId int64, userId int64) (bool, error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Delete("chat.chat_users").
+		Where(sq.Eq{usersChatIdColumn: chatId, usersUserIdColumn: userId}).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := r.conn.DB().Exec(ctx, db.Query{Name: "repository.postgres.RemoveMember", QueryRaw: sql}, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// SetRole меняет роль участника чата
+func (r *repo) SetRole(ctx context.Context, chatId int64, userId int64, role domain.Role) error {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Update("chat.chat_users").
+		Set(usersRoleColumn, string(role)).
+		Where(sq.Eq{usersChatIdColumn: chatId, usersUserIdColumn: userId}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.conn.DB().Exec(ctx, db.Query{Name: "repository.postgres.SetRole", QueryRaw: sql}, args...)
+
+	return err
+}
+
+// SetPinned закрепляет сообщение в чате, 0 снимает закрепление
+func (r *repo) SetPinned(ctx context.Context, chatId int64, messageId int64) error {
+	var pinned *int64
+	if messageId != 0 {
+		pinned = &messageId
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Update("chat.chats").
+		Set(pinnedColumn, pinned).
+		Where(sq.Eq{idColumn: chatId}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.conn.DB().Exec(ctx, db.Query{Name: "repository.postgres.SetPinned", QueryRaw: sql}, args...)
+
+	return err
+}
+
+// SetMute сохраняет запрет участнику писать в чат
+func (r *repo) SetMute(ctx context.Context, chatId int64, member domain.Member) error {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Update("chat.chat_users").
+		Set(usersMutedColumn, member.Muted).
+		Set(usersMutedUntilColumn, nullTime(member.MutedUntil)).
+		Where(sq.Eq{usersChatIdColumn: chatId, usersUserIdColumn: member.UserId}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.conn.DB().Exec(ctx, db.Query{Name: "repository.postgres.SetMute", QueryRaw: sql}, args...)
+
+	return err
+}
+
+// Ban блокирует пользователя в чате, повторная блокировка обновляет причину
+func (r *repo) Ban(ctx context.Context, chatId int64, userId int64, by int64, reason string) error {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Insert("chat.bans").
+		Columns(bansChatIdColumn, bansUserIdColumn, bansBannedByColumn, bansReasonColumn, bansBannedAtColumn).
+		Values(chatId, userId, by, reason, time.Now()).
+		Suffix("ON CONFLICT (chat_id, user_id) DO UPDATE SET banned_by = EXCLUDED.banned_by, reason = EXCLUDED.reason, banned_at = EXCLUDED.banned_at").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.conn.DB().Exec(ctx, db.Query{Name: "repository.postgres.Ban", QueryRaw: sql}, args...)
+
+	return err
+}
+
+func (r *repo) Unban(ctx context.Context, chatId int64, userId int64) (bool, error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Delete("chat.bans").
+		Where(sq.Eq{bansChatIdColumn: chatId, bansUserIdColumn: userId}).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := r.conn.DB().Exec(ctx, db.Query{Name: "repository.postgres.Unban", QueryRaw: sql}, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// Banned возвращает тех из userIds, кто заблокирован в чате
+func (r *repo) Banned(ctx context.Context, chatId int64, userIds []int64) ([]int64, error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Select(bansUserIdColumn).
+		From("chat.bans").
+		Where(sq.Eq{bansChatIdColumn: chatId, bansUserIdColumn: userIds}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.DB().Query(ctx, db.Query{Name: "repository.postgres.Banned", QueryRaw: sql}, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, pgx.RowTo[int64])
+}
+
+func (r *repo) Contacts(ctx context.Context, userId int64, userIds []int64) ([]int64, error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Select("other." + usersUserIdColumn).
+		Distinct().
+		From("chat.chat_users AS me").
+		Join("chat.chat_users AS other ON other." + usersChatIdColumn + " = me." + usersChatIdColumn).
+		Where(sq.Eq{"me." + usersUserIdColumn: userId, "other." + usersUserIdColumn: userIds}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.DB().Query(ctx, db.Query{Name: "repository.postgres.Contacts", QueryRaw: sql}, args...)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, pgx.RowTo[int64])
+}
+
+func (r *repo) MarkRead(ctx context.Context, chatId int64, position domain.ReadPosition) (bool, error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Update("chat.chat_users").
+		Set(usersLastReadColumn, position.MessageId).
+		Set(usersLastReadAtColumn, position.ReadAt).
+		Where(sq.Eq{usersChatIdColumn: chatId, usersUserIdColumn: position.UserId}).
+		// позиция только растет, иначе запоздавший запрос с другого устройства откатит ее назад
+		Where(sq.Lt{usersLastReadColumn: position.MessageId}).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
 
-	sql, args, err = psql.Select(usersUserIdColumn).
+	tag, err := r.conn.DB().Exec(ctx, db.Query{Name: "repository.postgres.MarkRead", QueryRaw: sql}, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *repo) ReadBy(ctx context.Context, chatId int64, messageId int64) ([]domain.ReadPosition, error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Select(usersUserIdColumn, usersUserNameColumn, usersLastReadColumn, usersLastReadAtColumn).
 		From("chat.chat_users").
 		Where(sq.Eq{usersChatIdColumn: chatId}).
+		Where(sq.GtOrEq{usersLastReadColumn: messageId}).
+		OrderBy(usersLastReadAtColumn).
 		ToSql()
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := r.conn.DB().Query(ctx, db.Query{Name: "repository.postgres.Get", QueryRaw: sql}, args...)
+	rows, err := r.conn.DB().Query(ctx, db.Query{Name: "repository.postgres.ReadBy", QueryRaw: sql}, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	userIds, err := pgx.CollectRows(rows, pgx.RowToStructByName[int64])
+	dtos, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.ReadPositionDTO])
 	if err != nil {
 		return nil, err
 	}
 
-	chat.UserIds = userIds
-	return &chat, nil
+	res := make([]domain.ReadPosition, 0, len(dtos))
+	for _, dto := range dtos {
+		position := domain.ReadPosition{UserId: dto.UserId, UserName: dto.UserName, MessageId: dto.MessageId}
+		if dto.ReadAt != nil {
+			position.ReadAt = *dto.ReadAt
+		}
+		res = append(res, position)
+	}
+
+	return res, nil
 }
 
-func (r *repo) Update(ctx context.Context, chat *domain.Chat) error {
-	err := r.conn.DB().ReadCommitted(ctx, func(ctx context.Context) error {
-		psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-		sql, args, err := psql.Delete("chat.chat_users").
-			Where(sq.Eq{usersChatIdColumn: chat.Id}).
-			ToSql()
-		if err != nil {
-			return err
-		}
+// ListForUser чаты пользователя с последним сообщением и числом непрочитанных, от самых активных
+func (r *repo) ListForUser(ctx context.Context, filter repository.ChatFilter) ([]*domain.Summary, error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	chats := sq.Select(
+		"c.id", "c.title", "c.type",
+		"(SELECT count(*) FROM chat.chat_users m WHERE m.chat_id = c.id) AS member_count",
+		"(SELECT count(*) FROM chat.messages um WHERE um.chat_id = c.id AND um.id > cu.last_read_message_id "+
+			"AND um.user_id <> cu.user_id AND um.deleted_at IS NULL) AS unread_count",
+		"COALESCE(lm.created_at, c.created_at) AS activity_at",
+		"lm.id AS last_message_id", "lm.user_id AS last_message_user_id",
+		"lm.text AS last_message_text", "lm.created_at AS last_message_at",
+	).
+		From("chat.chat_users cu").
+		Join("chat.chats c ON c.id = cu.chat_id").
+		JoinClause("LEFT JOIN LATERAL (SELECT id, user_id, text, created_at FROM chat.messages " +
+			"WHERE chat_id = c.id AND deleted_at IS NULL ORDER BY created_at DESC, id DESC LIMIT 1) lm ON true").
+		Where(sq.Eq{"cu.user_id": filter.UserId})
+
+	query := psql.Select("*").
+		FromSelect(chats, "t").
+		OrderBy("activity_at DESC", "id DESC").
+		Limit(filter.Limit)
+	if filter.After != nil {
+		query = query.Where(sq.Expr("(activity_at, id) < (?, ?)", filter.After.ActivityAt, filter.After.Id))
+	}
 
-		q := db.Query{Name: "repository.postgres.Update/chat_users", QueryRaw: sql}
-		_, err = r.conn.DB().Exec(ctx, q, args...)
-		if err != nil {
-			return err
-		}
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
 
-		insertQuery := psql.Insert("chat.chat_users").Columns(usersChatIdColumn, usersUserIdColumn)
+	rows, err := r.conn.DB().Query(ctx, db.Query{Name: "repository.postgres.ListForUser", QueryRaw: sql}, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-		for _, userId := range chat.UserIds {
-			insertQuery = insertQuery.Values(chat.Id, userId)
-		}
+	dtos, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.ChatSummaryDTO])
+	if err != nil {
+		return nil, err
+	}
 
-		query, args, err := insertQuery.ToSql()
-		if err != nil {
-			return err
+	res := make([]*domain.Summary, 0, len(dtos))
+	for _, dto := range dtos {
+		summary := &domain.Summary{
+			Id:          dto.Id,
+			Title:       dto.Title,
+			Type:        domain.Type(dto.Type),
+			MemberCount: dto.MemberCount,
+			UnreadCount: dto.UnreadCount,
+			ActivityAt:  dto.ActivityAt,
 		}
+		if dto.LastMessageId != nil {
+			summary.LastMessage = &message.Message{
+				Id:        *dto.LastMessageId,
+				ChatId:    dto.Id,
+				UserId:    *dto.LastMessageUserId,
+				Text:      *dto.LastMessageText,
+				CreatedAt: *dto.LastMessageAt,
+			}
+		}
+		res = append(res, summary)
+	}
 
-		q.QueryRaw = query
-		_, err = r.conn.DB().Exec(ctx, q, args...)
+	return res, nil
+}
 
-		return err
+// RemoveUser исключает пользователя из всех чатов, возвращает чаты и роли, с которыми он там состоял
+func (r *repo) RemoveUser(ctx context.Context, userId int64) ([]repository.Membership, error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Delete("chat.chat_users").
+		Where(sq.Eq{usersUserIdColumn: userId}).
+		Suffix(fmt.Sprintf("RETURNING %s, %s", usersChatIdColumn, usersRoleColumn)).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.DB().Query(ctx, db.Query{Name: "repository.postgres.RemoveUser", QueryRaw: sql}, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (repository.Membership, error) {
+		var m repository.Membership
+		err := row.Scan(&m.ChatId, &m.Role)
+
+		return m, err
 	})
+}
+
+// PromoteOwner делает владельцем чата старшего по роли из оставшихся участников, при равных ролях - самого давнего
+func (r *repo) PromoteOwner(ctx context.Context, chatId int64) (int64, bool, error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	// подзапрос собирается без своих плейсхолдеров, нумерацию $n делает внешний запрос
+	successor := sq.Select(usersUserIdColumn).
+		From("chat.chat_users").
+		Where(sq.Eq{usersChatIdColumn: chatId}).
+		OrderByClause(fmt.Sprintf("CASE %s WHEN ? THEN 0 WHEN ? THEN 1 ELSE 2 END", usersRoleColumn), string(domain.RoleAdmin), string(domain.RoleMember)).
+		OrderBy(usersJoinedAtColumn, usersUserIdColumn).
+		Limit(1).
+		Suffix("FOR UPDATE")
+
+	sql, args, err := psql.Update("chat.chat_users").
+		Set(usersRoleColumn, string(domain.RoleOwner)).
+		Where(sq.Eq{usersChatIdColumn: chatId}).
+		Where(successor.Prefix(usersUserIdColumn + " = (").Suffix(")")).
+		Suffix("RETURNING " + usersUserIdColumn).
+		ToSql()
+	if err != nil {
+		return 0, false, err
+	}
+
+	var ownerId int64
+	err = r.conn.DB().QueryRow(ctx, db.Query{Name: "repository.postgres.PromoteOwner", QueryRaw: sql}, args...).Scan(&ownerId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	return ownerId, true, nil
+}
+
+// RenameUser обновляет имя пользователя во всех чатах
+func (r *repo) RenameUser(ctx context.Context, userId int64, name string) error {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	sql, args, err := psql.Update("chat.chat_users").
+		Set(usersUserNameColumn, name).
+		Where(sq.Eq{usersUserIdColumn: userId}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.conn.DB().Exec(ctx, db.Query{Name: "repository.postgres.RenameUser", QueryRaw: sql}, args...)
 
 	return err
 }
@@ -0,0 +1,132 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	redislib "github.com/rkchv/chat/lib/redis"
+
+	"github.com/rkchv/chat/internal/domain/presence"
+	"github.com/rkchv/chat/internal/repository"
+)
+
+const (
+	instancesPrefix = "presence:instances:"
+	lastSeenPrefix  = "presence:last_seen:"
+)
+
+// touchScript отмечает экземпляр, где подключен пользователь, и заодно убирает истекшие отметки других.
+// KEYS[1] - экземпляры пользователя, KEYS[2] - время последнего появления, ARGV[1] - экземпляр, ARGV[2] - TTL в мс
+const touchScript = `
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local ttl = tonumber(ARGV[2])
+
+redis.call('ZADD', KEYS[1], now + ttl, ARGV[1])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+redis.call('PEXPIRE', KEYS[1], ttl)
+redis.call('SET', KEYS[2], now)
+
+return 1
+`
+
+// offlineScript снимает отметку экземпляра. KEYS как в touchScript, ARGV[1] - экземпляр
+const offlineScript = `
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('SET', KEYS[2], now)
+
+return 1
+`
+
+// getScript присутствие нескольких пользователей за один запрос. KEYS попарно: экземпляры и время последнего
+// появления каждого пользователя. Возвращает попарно число живых отметок и время последнего появления или nil
+const getScript = `
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local result = {}
+for i = 1, #KEYS, 2 do
+	result[#result + 1] = redis.call('ZCOUNT', KEYS[i], '(' .. now, '+inf')
+	result[#result + 1] = redis.call('GET', KEYS[i + 1])
+end
+
+return result
+`
+
+var _ repository.PresenceRepository = (*presenceRepo)(nil)
+
+// presenceRepo хранит присутствие в редисе. У пользователя есть множество экземпляров сервиса, где он подключен,
+// с временем истечения каждой отметки: пользователь в сети, пока не истекла хоть одна. Отключение на одном экземпляре
+// не уводит из сети подключения на других, а отметки упавшего экземпляра истекают сами
+type presenceRepo struct {
+	client     redislib.Client
+	ttl        time.Duration
+	instanceId string
+}
+
+func NewPresenceRepository(client redislib.Client, ttl time.Duration, instanceId string) repository.PresenceRepository {
+	return &presenceRepo{client: client, ttl: ttl, instanceId: instanceId}
+}
+
+func (r *presenceRepo) Touch(ctx context.Context, userId int64) error {
+	_, err := r.client.Eval(ctx, touchScript, []string{instancesKey(userId), lastSeenKey(userId)}, r.instanceId, r.ttl.Milliseconds())
+
+	return err
+}
+
+func (r *presenceRepo) Offline(ctx context.Context, userId int64) error {
+	_, err := r.client.Eval(ctx, offlineScript, []string{instancesKey(userId), lastSeenKey(userId)}, r.instanceId)
+
+	return err
+}
+
+func (r *presenceRepo) Get(ctx context.Context, userIds []int64) ([]presence.Presence, error) {
+	keys := make([]string, 0, len(userIds)*2)
+	for _, userId := range userIds {
+		keys = append(keys, instancesKey(userId), lastSeenKey(userId))
+	}
+
+	res, err := r.client.Eval(ctx, getScript, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := res.([]interface{})
+	if !ok || len(values) != len(keys) {
+		return nil, fmt.Errorf("unexpected presence script result: %v", res)
+	}
+
+	result := make([]presence.Presence, 0, len(userIds))
+	for i, userId := range userIds {
+		online, ok := values[2*i].(int64)
+		if !ok {
+			return nil, fmt.Errorf("unexpected presence script result: %v", res)
+		}
+
+		p := presence.Presence{UserId: userId, Online: online > 0}
+		if lastSeen, ok := values[2*i+1].([]byte); ok {
+			ms, err := strconv.ParseInt(string(lastSeen), 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			p.LastSeen = time.UnixMilli(ms)
+		}
+
+		result = append(result, p)
+	}
+
+	return result, nil
+}
+
+func instancesKey(userId int64) string {
+	return instancesPrefix + strconv.FormatInt(userId, 10)
+}
+
+func lastSeenKey(userId int64) string {
+	return lastSeenPrefix + strconv.FormatInt(userId, 10)
+}
@@ -3,18 +3,194 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	domain "github.com/rkchv/chat/internal/domain/chat"
+	"github.com/rkchv/chat/internal/domain/invite"
+	"github.com/rkchv/chat/internal/domain/message"
+	"github.com/rkchv/chat/internal/domain/outbox"
+	"github.com/rkchv/chat/internal/domain/presence"
 )
 
 type Repository interface {
 	Save(context.Context, *domain.Chat) error
 	Get(context.Context, int64) (*domain.Chat, error)
-	Update(context.Context, *domain.Chat) error
+	// GetForUpdate как Get, но блокирует участников чата до конца транзакции, чтобы роли не поменялись параллельно
+	GetForUpdate(context.Context, int64) (*domain.Chat, error)
 	Delete(ctx context.Context, id int64) error
+	// AddMembers добавляет участников в чат, возвращает id только тех, кого в чате еще не было
+	AddMembers(ctx context.Context, chatId int64, members []domain.Member) ([]int64, error)
+	// RemoveMember исключает пользователя из чата, возвращает false если он не был участником
+	RemoveMember(ctx context.Context, chatId int64, userId int64) (bool, error)
+	SetRole(ctx context.Context, chatId int64, userId int64, role domain.Role) error
+	// SetPinned закрепляет сообщение в чате, 0 снимает закрепление
+	SetPinned(ctx context.Context, chatId int64, messageId int64) error
+	SetMute(ctx context.Context, chatId int64, member domain.Member) error
+	Ban(ctx context.Context, chatId int64, userId int64, by int64, reason string) error
+	// Unban снимает блокировку, возвращает false если пользователь не был заблокирован
+	Unban(ctx context.Context, chatId int64, userId int64) (bool, error)
+	// Banned возвращает тех из userIds, кто заблокирован в чате
+	Banned(ctx context.Context, chatId int64, userIds []int64) ([]int64, error)
+	// Contacts возвращает тех из userIds, с кем у пользователя есть хотя бы один общий чат
+	Contacts(ctx context.Context, userId int64, userIds []int64) ([]int64, error)
+	// MarkRead сдвигает позицию прочтения участника вперед, возвращает false если она уже была не меньше
+	MarkRead(ctx context.Context, chatId int64, position domain.ReadPosition) (bool, error)
+	// ReadBy участники, прочитавшие чат хотя бы до сообщения messageId
+	ReadBy(ctx context.Context, chatId int64, messageId int64) ([]domain.ReadPosition, error)
+	// ListForUser чаты пользователя от самых активных
+	ListForUser(context.Context, ChatFilter) ([]*domain.Summary, error)
+	// RemoveUser исключает пользователя из всех чатов, возвращает, где и с какой ролью он состоял
+	RemoveUser(ctx context.Context, userId int64) ([]Membership, error)
+	// PromoteOwner назначает владельца чату, который его лишился. false - в чате никого не осталось
+	PromoteOwner(ctx context.Context, chatId int64) (int64, bool, error)
+	RenameUser(ctx context.Context, userId int64, name string) error
+}
+
+// MessageRepository хранилище сообщений чатов
+type MessageRepository interface {
+	Save(context.Context, *message.Message) error
+	History(context.Context, HistoryFilter) ([]*message.Message, error)
+	List(context.Context, MessageFilter) ([]*message.Message, error)
+	Get(ctx context.Context, id int64) (*message.Message, error)
+	Update(context.Context, *message.Message, message.Revision) error
+	// Search ищет сообщения по тексту в чатах, где пользователь участник, от новых к старым
+	Search(context.Context, SearchFilter) ([]*message.SearchHit, error)
+}
+
+// InviteRepository приглашения в чаты и журнал их использования
+type InviteRepository interface {
+	Save(context.Context, *invite.Invite) error
+	// GetByCode находит приглашение по коду и блокирует его до конца транзакции
+	GetByCode(ctx context.Context, code string) (*invite.Invite, error)
+	Get(ctx context.Context, id int64) (*invite.Invite, error)
+	Revoke(context.Context, *invite.Invite) error
+	// Use сохраняет счетчик использований и записывает, кто вступил по приглашению
+	Use(ctx context.Context, inv *invite.Invite, userId int64) error
+}
+
+// AttachmentRepository файлы, загруженные в чаты. Само содержимое лежит в хранилище файлов
+type AttachmentRepository interface {
+	Save(context.Context, *message.Attachment) error
+	Get(ctx context.Context, id int64) (*message.Attachment, error)
+	// Attach прикрепляет к сообщению загруженные автором в этот же чат и еще не прикрепленные файлы,
+	// возвращает те, которые удалось прикрепить
+	Attach(ctx context.Context, msg *message.Message, ids []int64) ([]message.Attachment, error)
+	// DeleteUnattached удаляет не больше limit файлов, загруженных раньше before и так и не прикрепленных
+	DeleteUnattached(ctx context.Context, before time.Time, limit uint64) ([]message.Attachment, error)
+	// Unreferenced возвращает те из ключей содержимого, на которые не ссылается ни одно вложение
+	Unreferenced(ctx context.Context, keys []string) ([]string, error)
+}
+
+// MentionRepository лента упоминаний пользователей
+type MentionRepository interface {
+	// Add добавляет сообщение в ленты упомянутых, возвращает тех, у кого его там еще не было
+	Add(ctx context.Context, msg *message.Message, userIds []int64) ([]int64, error)
+	// List упоминания пользователя в чатах, где он участник, от новых к старым
+	List(context.Context, MentionFilter) ([]*message.MentionItem, error)
+	// Unread сколько упоминаний пользователь еще не прочитал
+	Unread(ctx context.Context, userId int64) (int64, error)
+	// MarkRead отмечает прочитанными упоминания в чате до сообщения включительно
+	MarkRead(ctx context.Context, userId int64, chatId int64, messageId int64) error
+}
+
+// MentionFilter параметры выборки ленты упоминаний
+type MentionFilter struct {
+	UserId int64
+	// After ключ последнего упоминания предыдущей страницы: время и id сообщения
+	After      *MessageKey
+	UnreadOnly bool
+	Limit      uint64
+}
+
+// HistoryFilter параметры выборки истории чата. Задается либо SinceId, либо Since
+type HistoryFilter struct {
+	ChatId  int64
+	SinceId int64
+	Since   time.Time
+	// After продолжение выборки: сообщения строго после ключа последнего полученного
+	After *MessageKey
+	// Limit размер страницы
+	Limit uint64
+}
+
+// OutboxRepository события для публикации во внешние системы (transactional outbox)
+type OutboxRepository interface {
+	Add(context.Context, *outbox.Event) error
+	// TryLock берет до конца транзакции право публиковать события, false - публикует другой экземпляр
+	TryLock(ctx context.Context) (bool, error)
+	FetchUnpublished(ctx context.Context, limit uint64) ([]*outbox.Event, error)
+	MarkPublished(ctx context.Context, ids []int64) error
+	// DeletePublished удаляет не больше limit событий, опубликованных раньше before, возвращает сколько удалено
+	DeletePublished(ctx context.Context, before time.Time, limit uint64) (int64, error)
+}
+
+// MessageKey ключ сообщения для постраничной выборки (keyset pagination)
+type MessageKey struct {
+	CreatedAt time.Time
+	Id        int64
+}
+
+// ChatKey ключ чата для постраничной выборки списка чатов
+type ChatKey struct {
+	ActivityAt time.Time
+	Id         int64
+}
+
+// Membership участие пользователя в чате
+type Membership struct {
+	ChatId int64
+	Role   domain.Role
+}
+
+// ChatFilter параметры постраничной выборки чатов пользователя
+type ChatFilter struct {
+	UserId int64
+	// After ключ последнего чата предыдущей страницы, nil - первая страница
+	After *ChatKey
+	Limit uint64
+}
+
+// MessageFilter параметры постраничной выборки сообщений чата
+type MessageFilter struct {
+	ChatId int64
+	// After ключ последнего сообщения предыдущей страницы, nil - первая страница
+	After *MessageKey
+	// Newer выбирать от старых к новым, иначе от новых к старым
+	Newer    bool
+	SenderId int64
+	From     time.Time
+	To       time.Time
+	Limit    uint64
+}
+
+// SearchFilter параметры поиска сообщений
+type SearchFilter struct {
+	UserId int64
+	// Query запрос в синтаксисе веб-поиска: слова, "точная фраза", or, -исключение
+	Query string
+	// ChatId искать только в этом чате, 0 - во всех чатах пользователя
+	ChatId int64
+	After  *MessageKey
+	Limit  uint64
 }
 
 var (
 	// ErrChatNotFound пользователь отсутствует в хранилище
 	ErrChatNotFound = errors.New("чат не найден")
+	// ErrMessageNotFound сообщение отсутствует в хранилище
+	ErrMessageNotFound = errors.New("сообщение не найдено")
+	// ErrInviteNotFound приглашение отсутствует в хранилище
+	ErrInviteNotFound = errors.New("приглашение не найдено")
+	// ErrAttachmentNotFound вложение отсутствует в хранилище
+	ErrAttachmentNotFound = errors.New("вложение не найдено")
 )
+
+// PresenceRepository присутствие пользователей в сети, общее для всех экземпляров сервиса
+type PresenceRepository interface {
+	// Touch отмечает пользователя в сети. Без повторной отметки он уйдет из сети сам по истечении TTL
+	Touch(ctx context.Context, userId int64) error
+	// Offline снимает отметку этого экземпляра и запоминает время, когда пользователя видели последним.
+	// В сети пользователь остается, если он подключен через другие экземпляры
+	Offline(ctx context.Context, userId int64) error
+	Get(ctx context.Context, userIds []int64) ([]presence.Presence, error)
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/rkchv/chat/lib/blob"
+	syserr "github.com/rkchv/chat/lib/error"
+	"github.com/rkchv/chat/lib/logger"
+	"golang.org/x/exp/slog"
+
+	"github.com/rkchv/chat/internal/domain/message"
+	"github.com/rkchv/chat/internal/repository"
+	"github.com/rkchv/chat/internal/services/models"
+)
+
+const (
+	// sniffLen сколько первых байт файла нужно для определения его типа
+	sniffLen = 512
+	// staleUploadsBatch сколько неприкрепленных файлов удаляется за один запрос
+	staleUploadsBatch = 1000
+)
+
+// UploadAttachment сохраняет файл в хранилище. Загружать могут те, кто может писать в чат,
+// к сообщению файл прикрепляется отдельно при отправке
+func (s *Service) UploadAttachment(ctx context.Context, req models.UploadAttachment) (*message.Attachment, error) {
+	if err := s.attachmentPolicy.CheckName(req.FileName); err != nil {
+		return nil, attachmentError(err)
+	}
+
+	ch, err := s.getForMember(ctx, req.ChatId, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = ch.CanSend(req.UserId, time.Now()); err != nil {
+		return nil, chatError(err)
+	}
+
+	// тип определяем по содержимому, заявленному клиентом типу не доверяем
+	content := bufio.NewReaderSize(req.Content, sniffLen)
+	head, err := content.Peek(sniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if len(head) == 0 {
+		return nil, syserr.New("Пустой файл", syserr.InvalidArgument)
+	}
+
+	mimeType := http.DetectContentType(head)
+	if err = s.attachmentPolicy.CheckType(mimeType); err != nil {
+		return nil, attachmentError(err)
+	}
+
+	key, size, err := s.blobs.Put(ctx, &sizeLimiter{r: content, left: s.attachmentPolicy.MaxSize})
+	if err != nil {
+		if errors.Is(err, message.ErrTooLarge) {
+			return nil, attachmentError(err)
+		}
+		logger.GetLogger(ctx).Error("failed to store attachment", slog.String("error", err.Error()), slog.Int64("chatId", req.ChatId))
+		return nil, err
+	}
+
+	a := &message.Attachment{
+		ChatId:     req.ChatId,
+		UploadedBy: req.UserId,
+		BlobKey:    key,
+		FileName:   req.FileName,
+		MimeType:   mimeType,
+		Size:       size,
+		CreatedAt:  time.Now(),
+	}
+	if err = s.attachments.Save(ctx, a); err != nil {
+		logger.GetLogger(ctx).Error("failed to save attachment", slog.String("error", err.Error()), slog.Int64("chatId", req.ChatId))
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// OpenAttachment открывает файл на чтение, скачивать могут только участники чата.
+// Еще не прикрепленный к сообщению файл доступен только загрузившему его
+func (s *Service) OpenAttachment(ctx context.Context, attachmentId int64, userId int64) (*message.Attachment, io.ReadCloser, error) {
+	a, err := s.attachments.Get(ctx, attachmentId)
+	if err != nil {
+		if errors.Is(err, repository.ErrAttachmentNotFound) {
+			return nil, nil, syserr.New("Вложение не найдено", syserr.NotFound)
+		}
+		return nil, nil, err
+	}
+
+	if _, err = s.getForMember(ctx, a.ChatId, userId); err != nil {
+		return nil, nil, err
+	}
+
+	if a.MessageId == 0 && a.UploadedBy != userId {
+		return nil, nil, syserr.New("Вложение не найдено", syserr.NotFound)
+	}
+
+	// файлы удаленного сообщения удалены вместе с ним
+	if a.MessageId != 0 {
+		msg, err := s.getMessage(ctx, a.ChatId, a.MessageId)
+		if err != nil {
+			return nil, nil, err
+		}
+		if msg.IsDeleted() {
+			return nil, nil, syserr.New("Вложение не найдено", syserr.NotFound)
+		}
+	}
+
+	content, err := s.blobs.Open(ctx, a.BlobKey)
+	if err != nil {
+		logger.GetLogger(ctx).Error("failed to open attachment", slog.String("error", err.Error()), slog.Int64("attachmentId", a.Id))
+		if errors.Is(err, blob.ErrNotFound) {
+			return nil, nil, syserr.New("Содержимое вложения не найдено", syserr.NotFound)
+		}
+		return nil, nil, err
+	}
+
+	return a, content, nil
+}
+
+// DeleteStaleUploads удаляет файлы, загруженные раньше before и так и не прикрепленные к сообщению, вместе с их
+// содержимым, если на него не ссылаются другие вложения. Возвращает, сколько файлов удалено
+func (s *Service) DeleteStaleUploads(ctx context.Context, before time.Time) (int, error) {
+	log := logger.GetLogger(ctx)
+	total := 0
+	for {
+		deleted, err := s.attachments.DeleteUnattached(ctx, before, staleUploadsBatch)
+		if err != nil {
+			log.Error("failed to delete stale uploads", slog.String("error", err.Error()))
+			return total, err
+		}
+		total += len(deleted)
+
+		keys := make([]string, 0, len(deleted))
+		for _, a := range deleted {
+			if !slices.Contains(keys, a.BlobKey) {
+				keys = append(keys, a.BlobKey)
+			}
+		}
+
+		if len(keys) > 0 {
+			unreferenced, err := s.attachments.Unreferenced(ctx, keys)
+			if err != nil {
+				log.Error("failed to check stale uploads content", slog.String("error", err.Error()))
+				return total, err
+			}
+
+			for _, key := range unreferenced {
+				// строки уже удалены: содержимое, которое не удалось удалить сейчас, останется в хранилище без ссылок
+				if err = s.blobs.Delete(ctx, key, before); err != nil {
+					log.Error("failed to delete stale upload content", slog.String("error", err.Error()), slog.String("key", key))
+				}
+			}
+		}
+
+		if len(deleted) < staleUploadsBatch {
+			return total, nil
+		}
+	}
+}
+
+// attach прикрепляет загруженные файлы к только что сохраненному сообщению, вызывается в транзакции сохранения
+func (s *Service) attach(ctx context.Context, msg *message.Message, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	attached, err := s.attachments.Attach(ctx, msg, ids)
+	if err != nil {
+		return err
+	}
+
+	if len(attached) != len(ids) {
+		return attachmentError(message.ErrAttachment)
+	}
+	msg.Attachments = attached
+
+	return nil
+}
+
+func attachmentError(err error) error {
+	switch {
+	case errors.Is(err, message.ErrTooLarge):
+		return syserr.NewFromError(err, syserr.ResourceExhausted)
+	case errors.Is(err, message.ErrMimeType),
+		errors.Is(err, message.ErrFileName),
+		errors.Is(err, message.ErrAttachment),
+		errors.Is(err, message.ErrTooManyAttachments):
+		return syserr.NewFromError(err, syserr.InvalidArgument)
+	}
+
+	return err
+}
+
+// sizeLimiter читает не больше left байт, при превышении возвращает ErrTooLarge, и хранилище не сохраняет файл
+type sizeLimiter struct {
+	r    io.Reader
+	left int64
+}
+
+func (l *sizeLimiter) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.left -= int64(n)
+	if l.left < 0 {
+		return n, message.ErrTooLarge
+	}
+
+	return n, err
+}
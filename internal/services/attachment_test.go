@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rkchv/chat/lib/blob"
+	syserr "github.com/rkchv/chat/lib/error"
+
+	"github.com/rkchv/chat/internal/domain/chat"
+	"github.com/rkchv/chat/internal/domain/message"
+	"github.com/rkchv/chat/internal/repository"
+)
+
+// fakeAttachments вложения в памяти
+type fakeAttachments struct {
+	repository.AttachmentRepository
+	attachments []*message.Attachment
+}
+
+func (f *fakeAttachments) Get(_ context.Context, id int64) (*message.Attachment, error) {
+	for _, a := range f.attachments {
+		if a.Id == id {
+			return a, nil
+		}
+	}
+
+	return nil, repository.ErrAttachmentNotFound
+}
+
+func (f *fakeAttachments) DeleteUnattached(_ context.Context, before time.Time, limit uint64) ([]message.Attachment, error) {
+	var deleted []message.Attachment
+	f.attachments = slices.DeleteFunc(f.attachments, func(a *message.Attachment) bool {
+		if a.MessageId != 0 || !a.CreatedAt.Before(before) || uint64(len(deleted)) == limit {
+			return false
+		}
+		deleted = append(deleted, *a)
+		return true
+	})
+
+	return deleted, nil
+}
+
+func (f *fakeAttachments) Unreferenced(_ context.Context, keys []string) ([]string, error) {
+	var res []string
+	for _, key := range keys {
+		if !slices.ContainsFunc(f.attachments, func(a *message.Attachment) bool { return a.BlobKey == key }) {
+			res = append(res, key)
+		}
+	}
+
+	return res, nil
+}
+
+// fakeBlobs хранилище содержимого, запоминает удаленные ключи
+type fakeBlobs struct {
+	blob.Store
+	deleted []string
+}
+
+func (f *fakeBlobs) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(key)), nil
+}
+
+func (f *fakeBlobs) Delete(_ context.Context, key string, _ time.Time) error {
+	f.deleted = append(f.deleted, key)
+	return nil
+}
+
+func TestOpenAttachment(t *testing.T) {
+	at := time.Now()
+	s := &Service{
+		chatRepository: newFakeChats(chat.Chat{Id: 1, Members: []chat.Member{{UserId: 10}, {UserId: 20}}}),
+		messageRepository: &fakeMessages{messages: []*message.Message{
+			{Id: 1, ChatId: 1, CreatedAt: at},
+			{Id: 2, ChatId: 1, CreatedAt: at, DeletedAt: at},
+		}},
+		attachments: &fakeAttachments{attachments: []*message.Attachment{
+			{Id: 1, ChatId: 1, MessageId: 1, UploadedBy: 10, BlobKey: "sent"},
+			{Id: 2, ChatId: 1, MessageId: 2, UploadedBy: 10, BlobKey: "deleted"},
+			{Id: 3, ChatId: 1, UploadedBy: 10, BlobKey: "upload"},
+		}},
+		blobs: &fakeBlobs{},
+	}
+
+	tests := []struct {
+		name         string
+		attachmentId int64
+		userId       int64
+		code         syserr.Code
+	}{
+		{name: "файл сообщения", attachmentId: 1, userId: 20},
+		{name: "файл удаленного сообщения", attachmentId: 2, userId: 20, code: syserr.NotFound},
+		{name: "файл удаленного сообщения автору", attachmentId: 2, userId: 10, code: syserr.NotFound},
+		{name: "своя загрузка", attachmentId: 3, userId: 10},
+		{name: "чужая загрузка", attachmentId: 3, userId: 20, code: syserr.NotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, content, err := s.OpenAttachment(testContext(), tt.attachmentId, tt.userId)
+			if code := errorCode(err); code != tt.code {
+				t.Fatalf("err = %v (code %d), want code %d", err, code, tt.code)
+			}
+			if content != nil {
+				_ = content.Close()
+			}
+		})
+	}
+}
+
+func TestDeleteStaleUploads(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+	attachments := &fakeAttachments{attachments: []*message.Attachment{
+		{Id: 1, MessageId: 1, BlobKey: "shared", CreatedAt: old},
+		{Id: 2, BlobKey: "shared", CreatedAt: old},
+		{Id: 3, BlobKey: "stale", CreatedAt: old},
+		{Id: 4, BlobKey: "stale", CreatedAt: old},
+		{Id: 5, BlobKey: "fresh", CreatedAt: now},
+	}}
+	blobs := &fakeBlobs{}
+	s := &Service{attachments: attachments, blobs: blobs}
+
+	deleted, err := s.DeleteStaleUploads(testContext(), now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deleted != 3 {
+		t.Fatalf("deleted = %d, want 3", deleted)
+	}
+
+	var left []int64
+	for _, a := range attachments.attachments {
+		left = append(left, a.Id)
+	}
+	if !slices.Equal(left, []int64{1, 5}) {
+		t.Fatalf("attachments left = %v, want [1 5]", left)
+	}
+	// содержимое, на которое ссылается прикрепленный файл, остается
+	if !slices.Equal(blobs.deleted, []string{"stale"}) {
+		t.Fatalf("deleted content = %v, want [stale]", blobs.deleted)
+	}
+}
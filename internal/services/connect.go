@@ -3,25 +3,29 @@ package services
 import (
 	"context"
 	"log/slog"
+	"time"
 
 	"github.com/rkchv/chat/lib/logger"
 
 	"github.com/rkchv/chat/internal/services/models"
 )
 
+// Connect проверяет, что пользователь может подключиться к чату. Подключаться могут только участники
 func (s *Service) Connect(ctx context.Context, req models.Connect) error {
-	log := logger.GetLogger(ctx)
-	ch, err := s.chatRepository.Get(ctx, req.ChatId)
+	_, err := s.getForMember(ctx, req.ChatId, req.UserId)
 	if err != nil {
-		log.Error("failed to get chat", slog.String("error", err.Error()), slog.Any("request", req))
+		logger.GetLogger(ctx).Error("failed to connect to chat", slog.String("error", err.Error()), slog.Any("request", req))
 	}
 
-	ch.Connect(req.UserId)
-	err = s.chatRepository.Update(ctx, ch)
+	return err
+}
+
+// Typing проверяет, что пользователь может сообщить о наборе текста: набирать могут те, кто может писать в чат
+func (s *Service) Typing(ctx context.Context, req models.Connect) error {
+	ch, err := s.getForMember(ctx, req.ChatId, req.UserId)
 	if err != nil {
-		log.Error("failed to update chat", slog.String("error", err.Error()), slog.Any("request", req))
 		return err
 	}
 
-	return nil
+	return chatError(ch.CanSend(req.UserId, time.Now()))
 }
@@ -2,21 +2,64 @@ package services
 
 import (
 	"context"
+	"errors"
 
+	syserr "github.com/rkchv/chat/lib/error"
 	"github.com/rkchv/chat/lib/logger"
 	"golang.org/x/exp/slog"
 
 	"github.com/rkchv/chat/internal/domain/chat"
+	"github.com/rkchv/chat/internal/domain/outbox"
+	"github.com/rkchv/chat/internal/services/models"
 )
 
-func (s *Service) Create(ctx context.Context) (int64, error) {
+// Create создает чат вместе с начальными участниками
+func (s *Service) Create(ctx context.Context, req models.Create) (*chat.Chat, error) {
 	log := logger.GetLogger(ctx)
-	ch := chat.NewChat()
-	err := s.chatRepository.Save(ctx, &ch)
+	ch, err := chat.NewChat(chat.Member{UserId: req.CreatorId}, req.Title, req.Description, req.Type, req.Members)
+	if err != nil {
+		return nil, chatError(err)
+	}
 
+	err = s.txManager.ReadCommitted(ctx, func(ctx context.Context) error {
+		if err := s.chatRepository.Save(ctx, &ch); err != nil {
+			return err
+		}
+
+		return s.addEvent(ctx, outbox.TopicChats, outbox.ChatCreated, ch.Id, chatCreatedPayload{
+			chatPayload: chatPayload{ChatId: ch.Id, UserId: ch.CreatedBy, At: ch.CreatedAt},
+			Title:       ch.Title,
+			Type:        string(ch.Type),
+			Members:     ch.UserIds(),
+		})
+	})
 	if err != nil {
 		log.Error("failed to create chat", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return &ch, nil
+}
+
+// chatError переводит ошибки доменной модели чата в ошибки сервиса
+func chatError(err error) error {
+	switch {
+	case errors.Is(err, chat.ErrTitleRequired),
+		errors.Is(err, chat.ErrTitleTooLong),
+		errors.Is(err, chat.ErrDescriptionTooLong),
+		errors.Is(err, chat.ErrUnknownType),
+		errors.Is(err, chat.ErrUnknownRole):
+		return syserr.NewFromError(err, syserr.InvalidArgument)
+	case errors.Is(err, chat.ErrDirectMembers),
+		errors.Is(err, chat.ErrOwnerLeave):
+		return syserr.NewFromError(err, syserr.DomainLogic)
+	case errors.Is(err, chat.ErrPermissionDenied),
+		errors.Is(err, chat.ErrMuted),
+		errors.Is(err, chat.ErrBanned):
+		return syserr.NewFromError(err, syserr.PermissionDenied)
+	case errors.Is(err, chat.ErrNotMember):
+		return syserr.NewFromError(err, syserr.NotFound)
 	}
 
-	return ch.Id, err
+	return err
 }
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	syserr "github.com/rkchv/chat/lib/error"
+
+	"github.com/rkchv/chat/internal/repository"
+)
+
+// encodeCursor упаковывает ключ сообщения в непрозрачную для клиента строку
+func encodeCursor(key repository.MessageKey) string {
+	return formatCursor(key.CreatedAt, key.Id)
+}
+
+// decodeCursor распаковывает курсор, пустой курсор - первая страница
+func decodeCursor(cursor string) (*repository.MessageKey, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	at, id, err := parseCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	return &repository.MessageKey{CreatedAt: at, Id: id}, nil
+}
+
+// encodeChatCursor упаковывает ключ чата из списка чатов пользователя
+func encodeChatCursor(key repository.ChatKey) string {
+	return formatCursor(key.ActivityAt, key.Id)
+}
+
+// decodeChatCursor распаковывает курсор списка чатов, пустой курсор - первая страница
+func decodeChatCursor(cursor string) (*repository.ChatKey, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	at, id, err := parseCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	return &repository.ChatKey{ActivityAt: at, Id: id}, nil
+}
+
+func formatCursor(at time.Time, id int64) string {
+	raw := fmt.Sprintf("%d:%d", at.UnixNano(), id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func parseCursor(cursor string) (time.Time, int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, syserr.New("Некорректный курсор", syserr.InvalidArgument)
+	}
+
+	var nanos, id int64
+	if _, err = fmt.Sscanf(string(raw), "%d:%d", &nanos, &id); err != nil {
+		return time.Time{}, 0, syserr.New("Некорректный курсор", syserr.InvalidArgument)
+	}
+
+	return time.Unix(0, nanos).UTC(), id, nil
+}
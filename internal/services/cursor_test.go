@@ -0,0 +1,66 @@
+package services
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	syserr "github.com/rkchv/chat/lib/error"
+
+	"github.com/rkchv/chat/internal/repository"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		key  repository.MessageKey
+	}{
+		{name: "наносекунды сохраняются", key: repository.MessageKey{CreatedAt: time.Date(2026, 10, 18, 12, 0, 0, 123456789, time.UTC), Id: 42}},
+		{name: "другой часовой пояс", key: repository.MessageKey{CreatedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.FixedZone("MSK", 3*3600)), Id: 1}},
+		{name: "большой id", key: repository.MessageKey{CreatedAt: time.Unix(0, 1), Id: 1<<62 + 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := decodeCursor(encodeCursor(tt.key))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !key.CreatedAt.Equal(tt.key.CreatedAt) || key.Id != tt.key.Id {
+				t.Fatalf("decoded %+v, want %+v", *key, tt.key)
+			}
+		})
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	tests := []struct {
+		name    string
+		cursor  string
+		first   bool
+		invalid bool
+	}{
+		{name: "пустой курсор - первая страница", cursor: "", first: true},
+		{name: "не base64", cursor: "!!!", invalid: true},
+		{name: "нет id", cursor: "MTIz", invalid: true},
+		{name: "не числа", cursor: base64.RawURLEncoding.EncodeToString([]byte("a:b")), invalid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := decodeCursor(tt.cursor)
+			if tt.invalid {
+				if code := errorCode(err); code != syserr.InvalidArgument {
+					t.Fatalf("err = %v (code %d), want InvalidArgument", err, code)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.first != (key == nil) {
+				t.Fatalf("key = %v, first page = %v", key, tt.first)
+			}
+		})
+	}
+}
@@ -2,7 +2,7 @@ package services
 
 import (
 	"context"
-	"errors"
+	"time"
 
 	"github.com/rkchv/auth/pkg/user_v1/auth"
 	"github.com/rkchv/chat/lib/logger"
@@ -10,6 +10,9 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/exp/slog"
+
+	"github.com/rkchv/chat/internal/domain/chat"
+	"github.com/rkchv/chat/internal/domain/outbox"
 )
 
 func (s *Service) Delete(ctx context.Context, chatId int64) error {
@@ -23,19 +26,24 @@ func (s *Service) Delete(ctx context.Context, chatId int64) error {
 	tokenUser := auth.UserFromContext(ctx)
 	span.SetAttributes(attribute.Int64("user_id", tokenUser.ID))
 
-	span.AddEvent("call auth service")
-	canDel, err := s.authService.CanDelete(ctx, tokenUser.ID)
+	span.AddEvent("check chat role")
+	ch, err := s.getForMember(ctx, chatId, tokenUser.ID)
 	if err != nil {
-		log.Error("failed to call auth service", slog.String("error", err.Error()), slog.String("call_method", "CanDelete"))
 		return err
 	}
 
-	if !canDel {
-		return errors.New("недостаточно прав на удаление")
+	if !ch.Can(tokenUser.ID, chat.ActionDeleteChat) {
+		return chatError(chat.ErrPermissionDenied)
 	}
 
 	span.AddEvent("call repository")
-	err = s.chatRepository.Delete(ctx, chatId)
+	err = s.txManager.ReadCommitted(ctx, func(ctx context.Context) error {
+		if err := s.chatRepository.Delete(ctx, chatId); err != nil {
+			return err
+		}
+
+		return s.addEvent(ctx, outbox.TopicChats, outbox.ChatDeleted, chatId, chatPayload{ChatId: chatId, UserId: tokenUser.ID, At: time.Now()})
+	})
 	if err != nil {
 		log.Error("failed to delete chat", slog.String("error", err.Error()), slog.Int("chatId", int(chatId)))
 	}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	syserr "github.com/rkchv/chat/lib/error"
+	"github.com/rkchv/chat/lib/logger"
+	"golang.org/x/exp/slog"
+
+	"github.com/rkchv/chat/internal/domain/chat"
+	"github.com/rkchv/chat/internal/domain/message"
+	"github.com/rkchv/chat/internal/domain/outbox"
+	"github.com/rkchv/chat/internal/repository"
+	"github.com/rkchv/chat/internal/services/models"
+)
+
+// EditMessage меняет текст сообщения, прежний текст сохраняется ревизией. Упоминания разбираются заново,
+// в ленту попадают только те, кого в сообщении еще не упоминали
+func (s *Service) EditMessage(ctx context.Context, req models.EditMessage) (*message.Message, []int64, error) {
+	log := logger.GetLogger(ctx)
+	if strings.TrimSpace(req.Text) == "" {
+		return nil, nil, syserr.New("Пустое сообщение", syserr.InvalidArgument)
+	}
+
+	// править старые сообщения могут только те, кто сейчас может писать в чат
+	ch, err := s.getForMember(ctx, req.ChatId, req.UserId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = ch.CanSend(req.UserId, time.Now()); err != nil {
+		return nil, nil, chatError(err)
+	}
+
+	msg, err := s.getMessage(ctx, req.ChatId, req.MessageId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rev, err := msg.Edit(req.UserId, req.Text)
+	if err != nil {
+		return nil, nil, messageError(err)
+	}
+
+	var recipients []int64
+	msg.Mentions, recipients = ch.Mentions(req.UserId, req.Text)
+
+	mentioned, err := s.updateMessage(ctx, msg, rev, outbox.MessageEdited, recipients)
+	if err != nil {
+		log.Error("failed to edit message", slog.String("error", err.Error()), slog.Int64("messageId", req.MessageId))
+		return nil, nil, err
+	}
+
+	return msg, mentioned, nil
+}
+
+// DeleteMessage помечает сообщение удаленным. Удалить может автор или модератор чата
+func (s *Service) DeleteMessage(ctx context.Context, req models.DeleteMessage) (*message.Message, error) {
+	log := logger.GetLogger(ctx)
+	ch, err := s.getForMember(ctx, req.ChatId, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := s.getMessage(ctx, req.ChatId, req.MessageId)
+	if err != nil {
+		return nil, err
+	}
+
+	rev, err := msg.Delete(req.UserId, ch.Can(req.UserId, chat.ActionModerate))
+	if err != nil {
+		return nil, messageError(err)
+	}
+
+	_, err = s.updateMessage(ctx, msg, rev, outbox.MessageDeleted, nil)
+	if err != nil {
+		log.Error("failed to delete message", slog.String("error", err.Error()), slog.Int64("messageId", req.MessageId))
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// updateMessage сохраняет изменение сообщения, новые упоминания и событие о нем в одной транзакции.
+// Возвращает тех из recipients, кого упомянули впервые
+func (s *Service) updateMessage(ctx context.Context, msg *message.Message, rev message.Revision, eventType string, recipients []int64) ([]int64, error) {
+	var mentioned []int64
+	err := s.txManager.ReadCommitted(ctx, func(ctx context.Context) error {
+		if err := s.messageRepository.Update(ctx, msg, rev); err != nil {
+			return err
+		}
+
+		var err error
+		mentioned, err = s.mentions.Add(ctx, msg, recipients)
+		if err != nil {
+			return err
+		}
+
+		payload := newMessagePayload(msg)
+		payload.Mentioned = mentioned
+
+		return s.addEvent(ctx, outbox.TopicMessages, eventType, msg.ChatId, payload)
+	})
+	// сообщение удалили между чтением и записью
+	if errors.Is(err, repository.ErrMessageNotFound) {
+		return nil, messageError(message.ErrDeleted)
+	}
+
+	return mentioned, err
+}
+
+func (s *Service) getMessage(ctx context.Context, chatId int64, messageId int64) (*message.Message, error) {
+	msg, err := s.messageRepository.Get(ctx, messageId)
+	if err != nil {
+		if errors.Is(err, repository.ErrMessageNotFound) {
+			return nil, syserr.New("Сообщение не найдено", syserr.NotFound)
+		}
+
+		return nil, err
+	}
+
+	if msg.ChatId != chatId {
+		return nil, syserr.New("Сообщение не найдено", syserr.NotFound)
+	}
+
+	return msg, nil
+}
+
+// messageError переводит ошибки домена сообщений в ошибки сервиса
+func messageError(err error) error {
+	switch {
+	case errors.Is(err, message.ErrNotAuthor):
+		return syserr.NewFromError(err, syserr.PermissionDenied)
+	case errors.Is(err, message.ErrDeleted):
+		return syserr.NewFromError(err, syserr.NotFound)
+	}
+
+	return err
+}
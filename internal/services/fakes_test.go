@@ -0,0 +1,318 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rkchv/chat/lib/db"
+	syserr "github.com/rkchv/chat/lib/error"
+	"github.com/rkchv/chat/lib/logger"
+
+	"github.com/rkchv/chat/internal/domain/chat"
+	"github.com/rkchv/chat/internal/domain/invite"
+	"github.com/rkchv/chat/internal/domain/message"
+	"github.com/rkchv/chat/internal/domain/outbox"
+	"github.com/rkchv/chat/internal/repository"
+)
+
+// testContext контекст с логгером, без которого сервис не работает
+func testContext() context.Context {
+	return logger.AssignLogger(context.Background(), logger.SetupLogger(logger.Disable))
+}
+
+// errorCode код ошибки сервиса, OK для nil. Ошибки без кода считаются Internal
+func errorCode(err error) syserr.Code {
+	if err == nil {
+		return syserr.OK
+	}
+	if e := syserr.GetCommonError(err); e != nil {
+		return e.Code()
+	}
+
+	return syserr.Internal
+}
+
+// fakeTx выполняет транзакцию сразу, без отката
+type fakeTx struct{}
+
+func (fakeTx) BeginTx(_ context.Context, _ pgx.TxOptions) (pgx.Tx, error) {
+	return nil, errors.New("transactions are not supported by fakeTx")
+}
+
+func (fakeTx) ReadCommitted(ctx context.Context, f db.Handler) error {
+	return f(ctx)
+}
+
+// fakeChats чаты в памяти. Реализует только то, что нужно тестам, остальные методы паникуют
+type fakeChats struct {
+	repository.Repository
+	chats  map[int64]*chat.Chat
+	banned map[int64][]int64
+	// snapshots что вернет Get без блокировки, если задано: состояние до параллельного изменения
+	snapshots map[int64]*chat.Chat
+	// locked сколько раз чат читали с блокировкой участников
+	locked int
+	// writes сколько раз чат меняли
+	writes int
+	// read позиции прочтения по чату и пользователю
+	read map[[2]int64]int64
+}
+
+func newFakeChats(chats ...chat.Chat) *fakeChats {
+	f := &fakeChats{chats: make(map[int64]*chat.Chat), banned: make(map[int64][]int64)}
+	for i := range chats {
+		f.chats[chats[i].Id] = &chats[i]
+	}
+
+	return f
+}
+
+func (f *fakeChats) Get(_ context.Context, id int64) (*chat.Chat, error) {
+	if ch, ok := f.snapshots[id]; ok {
+		return copyChat(ch), nil
+	}
+
+	return f.current(id)
+}
+
+func (f *fakeChats) GetForUpdate(_ context.Context, id int64) (*chat.Chat, error) {
+	f.locked++
+	return f.current(id)
+}
+
+func (f *fakeChats) current(id int64) (*chat.Chat, error) {
+	ch, ok := f.chats[id]
+	if !ok {
+		return nil, repository.ErrChatNotFound
+	}
+
+	return copyChat(ch), nil
+}
+
+func copyChat(ch *chat.Chat) *chat.Chat {
+	c := *ch
+	c.Members = slices.Clone(ch.Members)
+
+	return &c
+}
+
+// Contacts собеседники по общим чатам
+func (f *fakeChats) Contacts(_ context.Context, userId int64, userIds []int64) ([]int64, error) {
+	var res []int64
+	for _, ch := range f.chats {
+		if !ch.IsMember(userId) {
+			continue
+		}
+		for _, id := range userIds {
+			if ch.IsMember(id) && !slices.Contains(res, id) {
+				res = append(res, id)
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func (f *fakeChats) MarkRead(_ context.Context, chatId int64, position chat.ReadPosition) (bool, error) {
+	if f.read == nil {
+		f.read = make(map[[2]int64]int64)
+	}
+
+	key := [2]int64{chatId, position.UserId}
+	if f.read[key] >= position.MessageId {
+		return false, nil
+	}
+	f.read[key] = position.MessageId
+
+	return true, nil
+}
+
+func (f *fakeChats) AddMembers(_ context.Context, chatId int64, members []chat.Member) ([]int64, error) {
+	f.writes++
+	ch := f.chats[chatId]
+	var added []int64
+	for _, m := range members {
+		if ch.IsMember(m.UserId) {
+			continue
+		}
+		ch.Members = append(ch.Members, m)
+		added = append(added, m.UserId)
+	}
+
+	return added, nil
+}
+
+func (f *fakeChats) RemoveMember(_ context.Context, chatId int64, userId int64) (bool, error) {
+	f.writes++
+	ch := f.chats[chatId]
+	i := slices.IndexFunc(ch.Members, func(m chat.Member) bool { return m.UserId == userId })
+	if i < 0 {
+		return false, nil
+	}
+	ch.Members = slices.Delete(ch.Members, i, i+1)
+
+	return true, nil
+}
+
+func (f *fakeChats) Ban(_ context.Context, chatId int64, userId int64, _ int64, _ string) error {
+	f.writes++
+	f.banned[chatId] = append(f.banned[chatId], userId)
+
+	return nil
+}
+
+func (f *fakeChats) SetMute(_ context.Context, _ int64, _ chat.Member) error {
+	f.writes++
+	return nil
+}
+
+func (f *fakeChats) SetPinned(_ context.Context, _ int64, _ int64) error {
+	f.writes++
+	return nil
+}
+
+func (f *fakeChats) SetRole(_ context.Context, _ int64, _ int64, _ chat.Role) error {
+	f.writes++
+	return nil
+}
+
+func (f *fakeChats) Banned(_ context.Context, chatId int64, userIds []int64) ([]int64, error) {
+	var res []int64
+	for _, id := range userIds {
+		if slices.Contains(f.banned[chatId], id) {
+			res = append(res, id)
+		}
+	}
+
+	return res, nil
+}
+
+func (f *fakeChats) RemoveUser(_ context.Context, userId int64) ([]repository.Membership, error) {
+	var res []repository.Membership
+	for _, ch := range f.chats {
+		i := slices.IndexFunc(ch.Members, func(m chat.Member) bool { return m.UserId == userId })
+		if i < 0 {
+			continue
+		}
+		res = append(res, repository.Membership{ChatId: ch.Id, Role: ch.Members[i].Role})
+		ch.Members = slices.Delete(ch.Members, i, i+1)
+	}
+
+	return res, nil
+}
+
+// PromoteOwner назначает владельцем первого из оставшихся участников
+func (f *fakeChats) PromoteOwner(_ context.Context, chatId int64) (int64, bool, error) {
+	ch := f.chats[chatId]
+	if len(ch.Members) == 0 {
+		return 0, false, nil
+	}
+	ch.Members[0].Role = chat.RoleOwner
+
+	return ch.Members[0].UserId, true, nil
+}
+
+// fakeInvites приглашения в памяти по коду
+type fakeInvites struct {
+	repository.InviteRepository
+	invites map[string]*invite.Invite
+}
+
+func (f *fakeInvites) GetByCode(_ context.Context, code string) (*invite.Invite, error) {
+	inv, ok := f.invites[code]
+	if !ok {
+		return nil, repository.ErrInviteNotFound
+	}
+
+	c := *inv
+	return &c, nil
+}
+
+func (f *fakeInvites) Use(_ context.Context, inv *invite.Invite, _ int64) error {
+	f.invites[inv.Code].Uses = inv.Uses
+	return nil
+}
+
+// fakeMentions запоминает, до какого сообщения прочитаны упоминания
+type fakeMentions struct {
+	repository.MentionRepository
+	readUpTo []int64
+}
+
+func (f *fakeMentions) MarkRead(_ context.Context, _ int64, _ int64, messageId int64) error {
+	f.readUpTo = append(f.readUpTo, messageId)
+	return nil
+}
+
+// fakeMessages сообщения в памяти, List выбирает их по ключу так же, как репозиторий
+type fakeMessages struct {
+	repository.MessageRepository
+	messages []*message.Message
+}
+
+func compareKeys(a, b repository.MessageKey) int {
+	if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+		return c
+	}
+
+	switch {
+	case a.Id < b.Id:
+		return -1
+	case a.Id > b.Id:
+		return 1
+	}
+
+	return 0
+}
+
+func messageKey(msg *message.Message) repository.MessageKey {
+	return repository.MessageKey{CreatedAt: msg.CreatedAt, Id: msg.Id}
+}
+
+func (f *fakeMessages) List(_ context.Context, filter repository.MessageFilter) ([]*message.Message, error) {
+	var res []*message.Message
+	for _, msg := range f.messages {
+		if msg.ChatId != filter.ChatId || msg.IsDeleted() {
+			continue
+		}
+		if filter.After != nil {
+			c := compareKeys(messageKey(msg), *filter.After)
+			if (filter.Newer && c <= 0) || (!filter.Newer && c >= 0) {
+				continue
+			}
+		}
+		res = append(res, msg)
+	}
+
+	slices.SortFunc(res, func(a, b *message.Message) int {
+		if filter.Newer {
+			return compareKeys(messageKey(a), messageKey(b))
+		}
+		return compareKeys(messageKey(b), messageKey(a))
+	})
+
+	return res[:min(uint64(len(res)), filter.Limit)], nil
+}
+
+func (f *fakeMessages) Get(_ context.Context, id int64) (*message.Message, error) {
+	for _, msg := range f.messages {
+		if msg.Id == id {
+			return msg, nil
+		}
+	}
+
+	return nil, repository.ErrMessageNotFound
+}
+
+// fakeOutbox запоминает события, записанные в outbox
+type fakeOutbox struct {
+	repository.OutboxRepository
+	events []*outbox.Event
+}
+
+func (f *fakeOutbox) Add(_ context.Context, event *outbox.Event) error {
+	f.events = append(f.events, event)
+	return nil
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+
+	"github.com/rkchv/chat/lib/logger"
+	"golang.org/x/exp/slog"
+
+	"github.com/rkchv/chat/internal/domain/message"
+	"github.com/rkchv/chat/internal/repository"
+	"github.com/rkchv/chat/internal/services/models"
+)
+
+// History отдает в page сохраненные сообщения чата, отправленные после заданного сообщения или момента времени.
+// Сообщения идут в хронологическом порядке страницами по req.Limit, пока не закончатся, так что пропусков не бывает
+// даже после долгого отключения
+func (s *Service) History(ctx context.Context, req models.History, page func([]*message.Message) error) error {
+	log := logger.GetLogger(ctx)
+	filter := repository.HistoryFilter{
+		ChatId:  req.ChatId,
+		SinceId: req.SinceId,
+		Since:   req.Since,
+		Limit:   req.Limit,
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultPageSize
+	}
+
+	for {
+		messages, err := s.messageRepository.History(ctx, filter)
+		if err != nil {
+			log.Error("failed to get chat history", slog.String("error", err.Error()), slog.Any("request", req))
+			return err
+		}
+
+		if len(messages) > 0 {
+			if err = page(messages); err != nil {
+				return err
+			}
+		}
+
+		if uint64(len(messages)) < filter.Limit {
+			return nil
+		}
+
+		last := messages[len(messages)-1]
+		filter.After = &repository.MessageKey{CreatedAt: last.CreatedAt, Id: last.Id}
+	}
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	syserr "github.com/rkchv/chat/lib/error"
+	"github.com/rkchv/chat/lib/logger"
+	"golang.org/x/exp/slog"
+
+	"github.com/rkchv/chat/internal/domain/chat"
+	"github.com/rkchv/chat/internal/domain/invite"
+	"github.com/rkchv/chat/internal/domain/outbox"
+	"github.com/rkchv/chat/internal/repository"
+	"github.com/rkchv/chat/internal/services/models"
+)
+
+// CreateInvite создает приглашение в чат и возвращает его вместе с подписанным токеном
+func (s *Service) CreateInvite(ctx context.Context, req models.CreateInvite) (*invite.Invite, string, error) {
+	if req.MaxUses < 0 {
+		return nil, "", syserr.New("Некорректное число использований", syserr.InvalidArgument)
+	}
+	if !req.ExpiresAt.IsZero() && !req.ExpiresAt.After(time.Now()) {
+		return nil, "", syserr.New("Срок действия приглашения уже истек", syserr.InvalidArgument)
+	}
+
+	ch, err := s.getForMember(ctx, req.ChatId, req.UserId)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err = ch.CanInvite(req.UserId, req.Role); err != nil {
+		return nil, "", chatError(err)
+	}
+
+	inv, err := invite.NewInvite(ch.Id, req.UserId, req.Role, req.MaxUses, req.ExpiresAt)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err = s.inviteRepository.Save(ctx, &inv); err != nil {
+		logger.GetLogger(ctx).Error("failed to save invite", slog.String("error", err.Error()), slog.Int64("chatId", req.ChatId))
+		return nil, "", err
+	}
+
+	return &inv, s.inviteSigner.Token(inv.Code), nil
+}
+
+// RevokeInvite отзывает приглашение. Отозвать может автор приглашения или тот, кто управляет участниками чата
+func (s *Service) RevokeInvite(ctx context.Context, inviteId int64, userId int64) error {
+	inv, err := s.inviteRepository.Get(ctx, inviteId)
+	if err != nil {
+		return inviteError(err)
+	}
+
+	ch, err := s.getForMember(ctx, inv.ChatId, userId)
+	if err != nil {
+		return err
+	}
+
+	if inv.CreatedBy != userId && !ch.Can(userId, chat.ActionManageMembers) {
+		return chatError(chat.ErrPermissionDenied)
+	}
+
+	inv.Revoke(time.Now())
+	if err = s.inviteRepository.Revoke(ctx, inv); err != nil {
+		logger.GetLogger(ctx).Error("failed to revoke invite", slog.String("error", err.Error()), slog.Int64("inviteId", inviteId))
+		return err
+	}
+
+	return nil
+}
+
+// JoinByInvite добавляет пользователя в чат по приглашению, возвращает чат и был ли пользователь добавлен.
+// Участнику, который уже в чате, приглашение не засчитывается
+func (s *Service) JoinByInvite(ctx context.Context, req models.JoinByInvite) (*chat.Chat, bool, error) {
+	code, err := s.inviteSigner.Code(req.Token)
+	if err != nil {
+		return nil, false, syserr.NewFromError(err, syserr.InvalidArgument)
+	}
+
+	var (
+		ch     *chat.Chat
+		joined bool
+	)
+	err = s.txManager.ReadCommitted(ctx, func(ctx context.Context) error {
+		// приглашение блокируется до конца транзакции, чтобы параллельные входы не превысили лимит использований
+		inv, err := s.inviteRepository.GetByCode(ctx, code)
+		if err != nil {
+			return inviteError(err)
+		}
+
+		ch, err = s.Get(ctx, inv.ChatId)
+		if err != nil {
+			return err
+		}
+
+		if ch.IsMember(req.UserId) {
+			return nil
+		}
+
+		if err = s.checkBanned(ctx, ch.Id, []int64{req.UserId}); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err = inv.Use(now); err != nil {
+			return inviteError(err)
+		}
+
+		member := chat.Member{UserId: req.UserId, UserName: req.UserName, Role: inv.Role}
+		if joined, err = ch.Join(member); err != nil {
+			return chatError(err)
+		}
+
+		if _, err = s.chatRepository.AddMembers(ctx, ch.Id, []chat.Member{member}); err != nil {
+			return err
+		}
+
+		if err = s.inviteRepository.Use(ctx, inv, req.UserId); err != nil {
+			return err
+		}
+
+		return s.addEvent(ctx, outbox.TopicMembers, outbox.MemberJoined, ch.Id, memberPayload{ChatId: ch.Id, UserId: req.UserId, By: inv.CreatedBy, Role: string(inv.Role), At: now})
+	})
+	if err != nil {
+		logger.GetLogger(ctx).Error("failed to join by invite", slog.String("error", err.Error()), slog.Int64("userId", req.UserId))
+		return nil, false, err
+	}
+
+	return ch, joined, nil
+}
+
+func inviteError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrInviteNotFound):
+		return syserr.New("Приглашение не найдено", syserr.NotFound)
+	case errors.Is(err, invite.ErrRevoked),
+		errors.Is(err, invite.ErrExpired),
+		errors.Is(err, invite.ErrUsedUp):
+		return syserr.NewFromError(err, syserr.DomainLogic)
+	}
+
+	return err
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	syserr "github.com/rkchv/chat/lib/error"
+
+	"github.com/rkchv/chat/internal/domain/chat"
+	"github.com/rkchv/chat/internal/domain/invite"
+	"github.com/rkchv/chat/internal/services/models"
+)
+
+func TestJoinByInviteUseLimit(t *testing.T) {
+	signer := invite.NewSigner([]byte("secret"))
+	chats := newFakeChats(chat.Chat{Id: 1, Type: chat.TypeGroup, Members: []chat.Member{{UserId: 1, Role: chat.RoleOwner}}})
+	invites := &fakeInvites{invites: map[string]*invite.Invite{
+		"once": {Id: 1, Code: "once", ChatId: 1, CreatedBy: 1, Role: chat.RoleMember, MaxUses: 1},
+	}}
+	s := &Service{txManager: fakeTx{}, chatRepository: chats, inviteRepository: invites, outboxRepository: &fakeOutbox{}, inviteSigner: signer}
+	token := signer.Token("once")
+
+	tests := []struct {
+		name   string
+		userId int64
+		joined bool
+		code   syserr.Code
+		uses   int
+	}{
+		{name: "участник не тратит использование", userId: 1, uses: 0},
+		{name: "первый вступивший", userId: 2, joined: true, uses: 1},
+		{name: "повторный вход уже вступившего", userId: 2, uses: 1},
+		{name: "лимит исчерпан", userId: 3, code: syserr.DomainLogic, uses: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, joined, err := s.JoinByInvite(testContext(), models.JoinByInvite{Token: token, UserId: tt.userId})
+			if code := errorCode(err); code != tt.code {
+				t.Fatalf("err = %v (code %d), want code %d", err, code, tt.code)
+			}
+			if joined != tt.joined {
+				t.Fatalf("joined = %v, want %v", joined, tt.joined)
+			}
+			if uses := invites.invites["once"].Uses; uses != tt.uses {
+				t.Fatalf("uses = %d, want %d", uses, tt.uses)
+			}
+		})
+	}
+
+	if chats.chats[1].IsMember(3) {
+		t.Fatal("user joined after the invite was used up")
+	}
+}
+
+func TestJoinByInviteRejected(t *testing.T) {
+	signer := invite.NewSigner([]byte("secret"))
+	now := time.Now()
+
+	tests := []struct {
+		name  string
+		token string
+		code  syserr.Code
+	}{
+		{name: "отозвано", token: signer.Token("revoked"), code: syserr.DomainLogic},
+		{name: "истекло", token: signer.Token("expired"), code: syserr.DomainLogic},
+		{name: "не найдено", token: signer.Token("missing"), code: syserr.NotFound},
+		{name: "чужая подпись", token: invite.NewSigner([]byte("other")).Token("revoked"), code: syserr.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chats := newFakeChats(chat.Chat{Id: 1, Type: chat.TypeGroup, Members: []chat.Member{{UserId: 1, Role: chat.RoleOwner}}})
+			invites := &fakeInvites{invites: map[string]*invite.Invite{
+				"revoked": {Id: 1, Code: "revoked", ChatId: 1, Role: chat.RoleMember, RevokedAt: now},
+				"expired": {Id: 2, Code: "expired", ChatId: 1, Role: chat.RoleMember, ExpiresAt: now.Add(-time.Minute)},
+			}}
+			s := &Service{txManager: fakeTx{}, chatRepository: chats, inviteRepository: invites, outboxRepository: &fakeOutbox{}, inviteSigner: signer}
+
+			_, _, err := s.JoinByInvite(testContext(), models.JoinByInvite{Token: tt.token, UserId: 2})
+			if code := errorCode(err); code != tt.code {
+				t.Fatalf("err = %v (code %d), want code %d", err, code, tt.code)
+			}
+			if chats.writes != 0 {
+				t.Fatal("rejected invite added a member")
+			}
+		})
+	}
+}
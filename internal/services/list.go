@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+
+	"github.com/rkchv/chat/lib/logger"
+	"golang.org/x/exp/slog"
+
+	"github.com/rkchv/chat/internal/repository"
+	"github.com/rkchv/chat/internal/services/models"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// ListMessages возвращает страницу истории чата и курсор для следующей страницы
+func (s *Service) ListMessages(ctx context.Context, req models.ListMessages) (*models.MessagesPage, error) {
+	log := logger.GetLogger(ctx)
+	after, err := decodeCursor(req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// историю читают только участники, исключенные и заблокированные теряют к ней доступ
+	if _, err = s.getForMember(ctx, req.ChatId, req.UserId); err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+	limit = min(limit, maxPageSize)
+
+	messages, err := s.messageRepository.List(ctx, repository.MessageFilter{
+		ChatId:   req.ChatId,
+		After:    after,
+		Newer:    req.Newer,
+		SenderId: req.SenderId,
+		From:     req.From,
+		To:       req.To,
+		Limit:    limit,
+	})
+	if err != nil {
+		log.Error("failed to list messages", slog.String("error", err.Error()), slog.Any("request", req))
+		return nil, err
+	}
+
+	page := &models.MessagesPage{Messages: messages}
+	// неполная страница - дальше сообщений нет
+	if uint64(len(messages)) == limit {
+		last := messages[len(messages)-1]
+		page.NextCursor = encodeCursor(repository.MessageKey{CreatedAt: last.CreatedAt, Id: last.Id})
+	}
+
+	return page, nil
+}
+
+// ListChats возвращает страницу чатов пользователя от самых активных
+func (s *Service) ListChats(ctx context.Context, req models.ListChats) (*models.ChatsPage, error) {
+	after, err := decodeChatCursor(req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+	limit = min(limit, maxPageSize)
+
+	chats, err := s.chatRepository.ListForUser(ctx, repository.ChatFilter{UserId: req.UserId, After: after, Limit: limit})
+	if err != nil {
+		logger.GetLogger(ctx).Error("failed to list chats", slog.String("error", err.Error()), slog.Any("request", req))
+		return nil, err
+	}
+
+	page := &models.ChatsPage{Chats: chats}
+	if uint64(len(chats)) == limit {
+		last := chats[len(chats)-1]
+		page.NextCursor = encodeChatCursor(repository.ChatKey{ActivityAt: last.ActivityAt, Id: last.Id})
+	}
+
+	return page, nil
+}
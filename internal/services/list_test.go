@@ -0,0 +1,111 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	syserr "github.com/rkchv/chat/lib/error"
+
+	"github.com/rkchv/chat/internal/domain/chat"
+	"github.com/rkchv/chat/internal/domain/message"
+	"github.com/rkchv/chat/internal/services/models"
+)
+
+func TestListMessagesPages(t *testing.T) {
+	at := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	// у сообщений 2-4 одинаковое время, страницы не должны терять и повторять их на границе
+	messages := &fakeMessages{messages: []*message.Message{
+		{Id: 1, ChatId: 1, CreatedAt: at},
+		{Id: 2, ChatId: 1, CreatedAt: at.Add(time.Second)},
+		{Id: 3, ChatId: 1, CreatedAt: at.Add(time.Second)},
+		{Id: 4, ChatId: 1, CreatedAt: at.Add(time.Second)},
+		{Id: 5, ChatId: 1, CreatedAt: at.Add(2 * time.Second), DeletedAt: at.Add(3 * time.Second)},
+		{Id: 6, ChatId: 1, CreatedAt: at.Add(2 * time.Second)},
+		{Id: 7, ChatId: 2, CreatedAt: at},
+	}}
+
+	tests := []struct {
+		name  string
+		newer bool
+		want  []int64
+	}{
+		{name: "от новых к старым", want: []int64{6, 4, 3, 2, 1}},
+		{name: "от старых к новым", newer: true, want: []int64{1, 2, 3, 4, 6}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Service{
+				chatRepository:    newFakeChats(chat.Chat{Id: 1, Members: []chat.Member{{UserId: 10, Role: chat.RoleMember}}}),
+				messageRepository: messages,
+			}
+
+			var (
+				got    []int64
+				cursor string
+				pages  int
+			)
+			for {
+				page, err := s.ListMessages(testContext(), models.ListMessages{ChatId: 1, UserId: 10, Cursor: cursor, Limit: 2, Newer: tt.newer})
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				for _, msg := range page.Messages {
+					got = append(got, msg.Id)
+				}
+				pages++
+
+				if page.NextCursor == "" {
+					break
+				}
+				cursor = page.NextCursor
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+			// две полные страницы и неполная последняя
+			if pages != 3 {
+				t.Fatalf("pages = %d, want 3", pages)
+			}
+		})
+	}
+}
+
+func TestListMessagesRequiresMembership(t *testing.T) {
+	chats := newFakeChats(chat.Chat{Id: 1, Members: []chat.Member{{UserId: 10, Role: chat.RoleMember}}})
+	chats.banned[1] = []int64{20}
+	s := &Service{chatRepository: chats, messageRepository: &fakeMessages{}}
+
+	tests := []struct {
+		name   string
+		chatId int64
+		userId int64
+		code   syserr.Code
+	}{
+		{name: "участник", chatId: 1, userId: 10, code: syserr.OK},
+		{name: "не участник", chatId: 1, userId: 30, code: syserr.PermissionDenied},
+		{name: "заблокированный", chatId: 1, userId: 20, code: syserr.PermissionDenied},
+		{name: "нет чата", chatId: 2, userId: 10, code: syserr.NotFound},
+		{name: "некорректный курсор", chatId: 1, userId: 10, code: syserr.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := models.ListMessages{ChatId: tt.chatId, UserId: tt.userId}
+			if tt.code == syserr.InvalidArgument {
+				req.Cursor = "!!!"
+			}
+
+			_, err := s.ListMessages(testContext(), req)
+			if code := errorCode(err); code != tt.code {
+				t.Fatalf("err = %v (code %d), want code %d", err, code, tt.code)
+			}
+		})
+	}
+}
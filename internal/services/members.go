@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	syserr "github.com/rkchv/chat/lib/error"
+	"github.com/rkchv/chat/lib/logger"
+	"golang.org/x/exp/slog"
+
+	"github.com/rkchv/chat/internal/domain/chat"
+	"github.com/rkchv/chat/internal/domain/outbox"
+	"github.com/rkchv/chat/internal/services/models"
+)
+
+// getForMember возвращает чат, если пользователь его участник
+func (s *Service) getForMember(ctx context.Context, chatId int64, userId int64) (*chat.Chat, error) {
+	ch, err := s.Get(ctx, chatId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ch.IsMember(userId) {
+		// заблокированному сообщаем причину отказа явно
+		if err = s.checkBanned(ctx, chatId, []int64{userId}); err != nil {
+			return nil, err
+		}
+
+		return nil, syserr.New("Пользователь не участник чата", syserr.PermissionDenied)
+	}
+
+	return ch, nil
+}
+
+// checkBanned возвращает ошибку, если кто-то из пользователей заблокирован в чате
+func (s *Service) checkBanned(ctx context.Context, chatId int64, userIds []int64) error {
+	banned, err := s.chatRepository.Banned(ctx, chatId, userIds)
+	if err != nil {
+		return err
+	}
+
+	if len(banned) > 0 {
+		return chatError(chat.ErrBanned)
+	}
+
+	return nil
+}
+
+// lockedChange проверяет change на чате, участники которого заблокированы до конца транзакции, и в той же транзакции
+// сохраняет результат через save. Иначе права проверялись бы по снимку, который параллельная операция уже изменила:
+// администратор, которого только что понизили, успел бы исключить участника, а владелец вышел бы из чата, который
+// в это же время передает. Отказ возвращает отдельно от ошибки записи, при отказе save не вызывается
+func (s *Service) lockedChange(ctx context.Context, chatId int64, by int64, change func(ch *chat.Chat) error, save func(ctx context.Context, ch *chat.Chat) error) (denied error, err error) {
+	if _, denied = s.getForMember(ctx, chatId, by); denied != nil {
+		return denied, nil
+	}
+
+	err = s.txManager.ReadCommitted(ctx, func(ctx context.Context) error {
+		ch, err := s.chatRepository.GetForUpdate(ctx, chatId)
+		if err != nil {
+			return err
+		}
+
+		if denied = change(ch); denied != nil {
+			return nil
+		}
+
+		return save(ctx, ch)
+	})
+
+	return denied, err
+}
+
+// AddMembers добавляет участников в чат, возвращает id только новых участников
+func (s *Service) AddMembers(ctx context.Context, req models.AddMembers) ([]int64, error) {
+	log := logger.GetLogger(ctx)
+	ch, err := s.getForMember(ctx, req.ChatId, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	added, err := ch.AddMembers(req.UserId, req.Members)
+	if err != nil {
+		return nil, chatError(err)
+	}
+
+	if len(added) > 0 {
+		if err = s.checkBanned(ctx, ch.Id, chat.UserIds(added)); err != nil {
+			return nil, err
+		}
+	}
+
+	var userIds []int64
+	err = s.txManager.ReadCommitted(ctx, func(ctx context.Context) error {
+		var err error
+		// состав мог измениться после выборки чата, поэтому новыми считаем тех, кого действительно добавила база
+		userIds, err = s.chatRepository.AddMembers(ctx, ch.Id, added)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, userId := range userIds {
+			err = s.addEvent(ctx, outbox.TopicMembers, outbox.MemberJoined, ch.Id, memberPayload{ChatId: ch.Id, UserId: userId, By: req.UserId, At: now})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to add members", slog.String("error", err.Error()), slog.Int64("chatId", req.ChatId))
+		return nil, err
+	}
+
+	return userIds, nil
+}
+
+// RemoveMember исключает участника из чата, исключать можно только участников с младшей ролью
+func (s *Service) RemoveMember(ctx context.Context, req models.RemoveMember) error {
+	eventType := outbox.MemberRemoved
+	if req.MemberId == req.UserId {
+		eventType = outbox.MemberLeft
+	}
+
+	denied, err := s.lockedChange(ctx, req.ChatId, req.UserId, func(ch *chat.Chat) error {
+		return ch.RemoveMember(req.UserId, req.MemberId)
+	}, func(ctx context.Context, ch *chat.Chat) error {
+		removed, err := s.chatRepository.RemoveMember(ctx, ch.Id, req.MemberId)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return syserr.New("Пользователь не участник чата", syserr.NotFound)
+		}
+
+		return s.addEvent(ctx, outbox.TopicMembers, eventType, ch.Id, memberPayload{ChatId: ch.Id, UserId: req.MemberId, By: req.UserId, At: time.Now()})
+	})
+	if err != nil {
+		logger.GetLogger(ctx).Error("failed to remove member", slog.String("error", err.Error()), slog.Int64("chatId", req.ChatId), slog.Int64("userId", req.MemberId))
+		return err
+	}
+
+	return chatError(denied)
+}
+
+// LeaveChat пользователь сам выходит из чата, владелец перед выходом должен передать владение
+func (s *Service) LeaveChat(ctx context.Context, chatId int64, userId int64) error {
+	return s.RemoveMember(ctx, models.RemoveMember{ChatId: chatId, UserId: userId, MemberId: userId})
+}
+
+// ListMembers участники чата, список доступен только участникам
+func (s *Service) ListMembers(ctx context.Context, chatId int64, userId int64) ([]chat.Member, error) {
+	ch, err := s.getForMember(ctx, chatId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return ch.Members, nil
+}
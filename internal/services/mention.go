@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+
+	"github.com/rkchv/chat/lib/logger"
+	"golang.org/x/exp/slog"
+
+	"github.com/rkchv/chat/internal/repository"
+	"github.com/rkchv/chat/internal/services/models"
+)
+
+// ListMentions возвращает страницу ленты упоминаний пользователя и общее число непрочитанных.
+// Упоминания читаются вместе с чатом через MarkRead
+func (s *Service) ListMentions(ctx context.Context, req models.ListMentions) (*models.MentionsPage, error) {
+	log := logger.GetLogger(ctx)
+	after, err := decodeCursor(req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+	limit = min(limit, maxPageSize)
+
+	items, err := s.mentions.List(ctx, repository.MentionFilter{
+		UserId:     req.UserId,
+		After:      after,
+		UnreadOnly: req.UnreadOnly,
+		Limit:      limit,
+	})
+	if err != nil {
+		log.Error("failed to list mentions", slog.String("error", err.Error()), slog.Any("request", req))
+		return nil, err
+	}
+
+	unread, err := s.mentions.Unread(ctx, req.UserId)
+	if err != nil {
+		log.Error("failed to count unread mentions", slog.String("error", err.Error()), slog.Int64("userId", req.UserId))
+		return nil, err
+	}
+
+	page := &models.MentionsPage{Mentions: items, Unread: unread}
+	if uint64(len(items)) == limit {
+		last := items[len(items)-1].Message
+		page.NextCursor = encodeCursor(repository.MessageKey{CreatedAt: last.CreatedAt, Id: last.Id})
+	}
+
+	return page, nil
+}
@@ -0,0 +1,11 @@
+package models
+
+import "github.com/rkchv/chat/internal/domain/chat"
+
+type Create struct {
+	CreatorId   int64
+	Title       string
+	Description string
+	Type        chat.Type
+	Members     []chat.Member
+}
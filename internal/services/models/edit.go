@@ -0,0 +1,14 @@
+package models
+
+type EditMessage struct {
+	ChatId    int64
+	MessageId int64
+	UserId    int64
+	Text      string
+}
+
+type DeleteMessage struct {
+	ChatId    int64
+	MessageId int64
+	UserId    int64
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+type History struct {
+	ChatId  int64
+	SinceId int64
+	Since   time.Time
+	Limit   uint64
+}
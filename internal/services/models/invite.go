@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/rkchv/chat/internal/domain/chat"
+)
+
+type CreateInvite struct {
+	ChatId int64
+	UserId int64
+	Role   chat.Role
+	// MaxUses 0 - без ограничений
+	MaxUses int
+	// ExpiresAt нулевое значение - бессрочно
+	ExpiresAt time.Time
+}
+
+type JoinByInvite struct {
+	Token    string
+	UserId   int64
+	UserName string
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/rkchv/chat/internal/domain/chat"
+	"github.com/rkchv/chat/internal/domain/message"
+)
+
+type ListMessages struct {
+	ChatId   int64
+	UserId   int64
+	Cursor   string
+	Limit    uint64
+	Newer    bool
+	SenderId int64
+	From     time.Time
+	To       time.Time
+}
+
+type MessagesPage struct {
+	Messages   []*message.Message
+	NextCursor string
+}
+
+type ListChats struct {
+	UserId int64
+	Cursor string
+	Limit  uint64
+}
+
+type ChatsPage struct {
+	Chats      []*chat.Summary
+	NextCursor string
+}
+
+type SearchMessages struct {
+	UserId int64
+	Query  string
+	ChatId int64
+	Cursor string
+	Limit  uint64
+}
+
+type SearchPage struct {
+	Hits       []*message.SearchHit
+	NextCursor string
+}
+
+type ListMentions struct {
+	UserId     int64
+	Cursor     string
+	Limit      uint64
+	UnreadOnly bool
+}
+
+type MentionsPage struct {
+	Mentions   []*message.MentionItem
+	NextCursor string
+	// Unread сколько всего непрочитанных упоминаний у пользователя
+	Unread int64
+}
@@ -0,0 +1,46 @@
+package models
+
+import "github.com/rkchv/chat/internal/domain/chat"
+
+type AddMembers struct {
+	ChatId int64
+	// UserId кто добавляет участников
+	UserId  int64
+	Members []chat.Member
+}
+
+type RemoveMember struct {
+	ChatId int64
+	// UserId кто исключает участника
+	UserId   int64
+	MemberId int64
+}
+
+type SetMemberRole struct {
+	ChatId int64
+	// UserId кто меняет роль
+	UserId   int64
+	MemberId int64
+	Role     chat.Role
+}
+
+type TransferOwnership struct {
+	ChatId int64
+	// UserId текущий владелец
+	UserId     int64
+	NewOwnerId int64
+}
+
+type PinMessage struct {
+	ChatId int64
+	UserId int64
+	// MessageId закрепляемое сообщение, 0 снимает закрепление
+	MessageId int64
+}
+
+// RemovedUser чаты, из которых исключен удаленный пользователь
+type RemovedUser struct {
+	ChatIds []int64
+	// Owners новые владельцы чатов, которыми он владел, по id чата
+	Owners map[int64]int64
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type BanMember struct {
+	ChatId int64
+	// UserId модератор
+	UserId   int64
+	MemberId int64
+	Reason   string
+}
+
+type MuteMember struct {
+	ChatId int64
+	// UserId модератор
+	UserId   int64
+	MemberId int64
+	// Until нулевое значение - бессрочно
+	Until time.Time
+}
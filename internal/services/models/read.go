@@ -0,0 +1,7 @@
+package models
+
+type MarkRead struct {
+	ChatId    int64
+	UserId    int64
+	MessageId int64
+}
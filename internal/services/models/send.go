@@ -0,0 +1,18 @@
+package models
+
+import "io"
+
+type SendMessage struct {
+	ChatId int64
+	UserId int64
+	Text   string
+	// AttachmentIds загруженные отправителем в этот чат файлы, которые прикрепляются к сообщению
+	AttachmentIds []int64
+}
+
+type UploadAttachment struct {
+	ChatId   int64
+	UserId   int64
+	FileName string
+	Content  io.Reader
+}
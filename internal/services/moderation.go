@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	syserr "github.com/rkchv/chat/lib/error"
+	"github.com/rkchv/chat/lib/logger"
+	"golang.org/x/exp/slog"
+
+	"github.com/rkchv/chat/internal/domain/chat"
+	"github.com/rkchv/chat/internal/domain/outbox"
+	"github.com/rkchv/chat/internal/services/models"
+)
+
+// BanMember исключает пользователя из чата и запрещает ему возвращаться.
+// Возвращает true, если пользователь был участником, тогда его подключения нужно закрыть
+func (s *Service) BanMember(ctx context.Context, req models.BanMember) (bool, error) {
+	var wasMember bool
+	denied, err := s.lockedChange(ctx, req.ChatId, req.UserId, func(ch *chat.Chat) error {
+		var err error
+		wasMember, err = ch.Ban(req.UserId, req.MemberId)
+		return err
+	}, func(ctx context.Context, ch *chat.Chat) error {
+		if wasMember {
+			if _, err := s.chatRepository.RemoveMember(ctx, ch.Id, req.MemberId); err != nil {
+				return err
+			}
+		}
+
+		if err := s.chatRepository.Ban(ctx, ch.Id, req.MemberId, req.UserId, req.Reason); err != nil {
+			return err
+		}
+
+		return s.addEvent(ctx, outbox.TopicMembers, outbox.MemberBanned, ch.Id, memberPayload{ChatId: ch.Id, UserId: req.MemberId, By: req.UserId, Reason: req.Reason, At: time.Now()})
+	})
+	if err != nil {
+		logger.GetLogger(ctx).Error("failed to ban member", slog.String("error", err.Error()), slog.Int64("chatId", req.ChatId), slog.Int64("userId", req.MemberId))
+		return false, err
+	}
+	if denied != nil {
+		return false, chatError(denied)
+	}
+
+	return wasMember, nil
+}
+
+// UnbanMember снимает блокировку, пользователь сможет снова вступить в чат
+func (s *Service) UnbanMember(ctx context.Context, chatId int64, userId int64, memberId int64) error {
+	ch, err := s.getForMember(ctx, chatId, userId)
+	if err != nil {
+		return err
+	}
+
+	if !ch.Can(userId, chat.ActionModerate) {
+		return chatError(chat.ErrPermissionDenied)
+	}
+
+	err = s.txManager.ReadCommitted(ctx, func(ctx context.Context) error {
+		unbanned, err := s.chatRepository.Unban(ctx, chatId, memberId)
+		if err != nil {
+			return err
+		}
+		if !unbanned {
+			return syserr.New("Пользователь не заблокирован", syserr.NotFound)
+		}
+
+		return s.addEvent(ctx, outbox.TopicMembers, outbox.MemberUnbanned, chatId, memberPayload{ChatId: chatId, UserId: memberId, By: userId, At: time.Now()})
+	})
+	if err != nil {
+		logger.GetLogger(ctx).Error("failed to unban member", slog.String("error", err.Error()), slog.Int64("chatId", chatId), slog.Int64("userId", memberId))
+	}
+
+	return err
+}
+
+// MuteMember запрещает участнику писать в чат, читать он может по-прежнему
+func (s *Service) MuteMember(ctx context.Context, req models.MuteMember) error {
+	if !req.Until.IsZero() && !req.Until.After(time.Now()) {
+		return syserr.New("Срок запрета уже истек", syserr.InvalidArgument)
+	}
+
+	return s.changeMute(ctx, req.ChatId, req.UserId, req.MemberId, outbox.MemberMuted, func(ch *chat.Chat) error {
+		return ch.Mute(req.UserId, req.MemberId, req.Until)
+	})
+}
+
+// UnmuteMember снимает запрет писать
+func (s *Service) UnmuteMember(ctx context.Context, chatId int64, userId int64, memberId int64) error {
+	return s.changeMute(ctx, chatId, userId, memberId, outbox.MemberUnmuted, func(ch *chat.Chat) error {
+		return ch.Unmute(userId, memberId)
+	})
+}
+
+// changeMute применяет change к заблокированным участникам чата и сохраняет запрет участника вместе с событием
+func (s *Service) changeMute(ctx context.Context, chatId int64, by int64, memberId int64, eventType string, change func(ch *chat.Chat) error) error {
+	denied, err := s.lockedChange(ctx, chatId, by, change, func(ctx context.Context, ch *chat.Chat) error {
+		member, _ := ch.Member(memberId)
+		if err := s.chatRepository.SetMute(ctx, ch.Id, member); err != nil {
+			return err
+		}
+
+		payload := memberPayload{ChatId: ch.Id, UserId: memberId, By: by, At: time.Now()}
+		if !member.MutedUntil.IsZero() {
+			payload.Until = &member.MutedUntil
+		}
+
+		return s.addEvent(ctx, outbox.TopicMembers, eventType, ch.Id, payload)
+	})
+	if err != nil {
+		logger.GetLogger(ctx).Error("failed to save member mute", slog.String("error", err.Error()), slog.Int64("chatId", chatId), slog.Int64("userId", memberId))
+		return err
+	}
+
+	return chatError(denied)
+}
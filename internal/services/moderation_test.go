@@ -0,0 +1,91 @@
+package services
+
+import (
+	"testing"
+
+	syserr "github.com/rkchv/chat/lib/error"
+
+	"github.com/rkchv/chat/internal/domain/chat"
+	"github.com/rkchv/chat/internal/services/models"
+)
+
+// TestChecksUseLockedMembers администратора 2 понизили, пока шел запрос: его права проверяются по заблокированным
+// участникам, а не по снимку, прочитанному до изменения
+func TestChecksUseLockedMembers(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(s *Service) error
+	}{
+		{name: "исключение", change: func(s *Service) error {
+			return s.RemoveMember(testContext(), models.RemoveMember{ChatId: 1, UserId: 2, MemberId: 3})
+		}},
+		{name: "блокировка", change: func(s *Service) error {
+			_, err := s.BanMember(testContext(), models.BanMember{ChatId: 1, UserId: 2, MemberId: 3})
+			return err
+		}},
+		{name: "запрет писать", change: func(s *Service) error {
+			return s.MuteMember(testContext(), models.MuteMember{ChatId: 1, UserId: 2, MemberId: 3})
+		}},
+		{name: "закрепление", change: func(s *Service) error {
+			return s.PinMessage(testContext(), models.PinMessage{ChatId: 1, UserId: 2})
+		}},
+		{name: "смена роли", change: func(s *Service) error {
+			return s.SetMemberRole(testContext(), models.SetMemberRole{ChatId: 1, UserId: 2, MemberId: 3, Role: chat.RoleReadonly})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chats := newFakeChats(chat.Chat{Id: 1, Type: chat.TypeGroup, Members: []chat.Member{
+				{UserId: 1, Role: chat.RoleOwner},
+				{UserId: 2, Role: chat.RoleReadonly},
+				{UserId: 3, Role: chat.RoleMember},
+			}})
+			chats.snapshots = map[int64]*chat.Chat{1: {Id: 1, Type: chat.TypeGroup, Members: []chat.Member{
+				{UserId: 1, Role: chat.RoleOwner},
+				{UserId: 2, Role: chat.RoleAdmin},
+				{UserId: 3, Role: chat.RoleMember},
+			}}}
+			events := &fakeOutbox{}
+			s := &Service{txManager: fakeTx{}, chatRepository: chats, outboxRepository: events}
+
+			err := tt.change(s)
+			if code := errorCode(err); code != syserr.PermissionDenied {
+				t.Fatalf("err = %v (code %d), want PermissionDenied", err, code)
+			}
+			if chats.locked != 1 {
+				t.Fatalf("chat was locked %d times, want 1", chats.locked)
+			}
+			if chats.writes != 0 || len(events.events) != 0 {
+				t.Fatalf("denied change wrote %d rows and %d events", chats.writes, len(events.events))
+			}
+		})
+	}
+}
+
+// TestLeaveAfterTransfer владелец передал чат и вышел: выход проверяется по роли после передачи
+func TestLeaveAfterTransfer(t *testing.T) {
+	chats := newFakeChats(chat.Chat{Id: 1, Type: chat.TypeGroup, Members: []chat.Member{
+		{UserId: 1, Role: chat.RoleOwner},
+		{UserId: 2, Role: chat.RoleMember},
+	}})
+	events := &fakeOutbox{}
+	s := &Service{txManager: fakeTx{}, chatRepository: chats, outboxRepository: events}
+
+	// пока владение не передано, владелец выйти не может
+	if err := s.LeaveChat(testContext(), 1, 1); errorCode(err) != syserr.DomainLogic {
+		t.Fatalf("owner left before transfer: %v", err)
+	}
+
+	// передача прошла по снимку, снятому до нее: роль в базе уже администратор
+	chats.snapshots = map[int64]*chat.Chat{1: copyChat(chats.chats[1])}
+	chats.chats[1].Members[0].Role = chat.RoleAdmin
+	chats.chats[1].Members[1].Role = chat.RoleOwner
+
+	if err := s.LeaveChat(testContext(), 1, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(chats.chats[1].Members) != 1 || chats.chats[1].Members[0].Role != chat.RoleOwner {
+		t.Fatalf("members after leave = %+v, want only the new owner", chats.chats[1].Members)
+	}
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/rkchv/chat/internal/domain/message"
+	"github.com/rkchv/chat/internal/domain/outbox"
+)
+
+type chatPayload struct {
+	ChatId int64     `json:"chat_id"`
+	UserId int64     `json:"user_id,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+type chatCreatedPayload struct {
+	chatPayload
+	Title   string  `json:"title"`
+	Type    string  `json:"type"`
+	Members []int64 `json:"members"`
+}
+
+type memberPayload struct {
+	ChatId int64 `json:"chat_id"`
+	UserId int64 `json:"user_id"`
+	// By кто добавил или исключил участника
+	By     int64      `json:"by,omitempty"`
+	Role   string     `json:"role,omitempty"`
+	Reason string     `json:"reason,omitempty"`
+	Until  *time.Time `json:"until,omitempty"`
+	At     time.Time  `json:"at"`
+}
+
+type pinPayload struct {
+	ChatId    int64     `json:"chat_id"`
+	MessageId int64     `json:"message_id"`
+	UserId    int64     `json:"user_id"`
+	At        time.Time `json:"at"`
+}
+
+type messagePayload struct {
+	Id        int64      `json:"id"`
+	ChatId    int64      `json:"chat_id"`
+	UserId    int64      `json:"user_id"`
+	Text      string     `json:"text"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// Attachments id прикрепленных файлов
+	Attachments []int64 `json:"attachments,omitempty"`
+	// Mentioned кого в сообщении упомянули впервые
+	Mentioned []int64 `json:"mentioned,omitempty"`
+}
+
+func newMessagePayload(msg *message.Message) messagePayload {
+	p := messagePayload{
+		Id:        msg.Id,
+		ChatId:    msg.ChatId,
+		UserId:    msg.UserId,
+		Text:      msg.Text,
+		CreatedAt: msg.CreatedAt,
+	}
+	if !msg.UpdatedAt.IsZero() {
+		p.UpdatedAt = &msg.UpdatedAt
+	}
+	if msg.IsDeleted() {
+		p.DeletedAt = &msg.DeletedAt
+	}
+	for _, a := range msg.Attachments {
+		p.Attachments = append(p.Attachments, a.Id)
+	}
+
+	return p
+}
+
+// addEvent пишет событие в outbox, вызывается внутри транзакции изменения, к которому относится событие
+func (s *Service) addEvent(ctx context.Context, topic string, eventType string, chatId int64, payload any) error {
+	event, err := outbox.NewChatEvent(topic, eventType, chatId, payload)
+	if err != nil {
+		return err
+	}
+
+	return s.outboxRepository.Add(ctx, &event)
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	syserr "github.com/rkchv/chat/lib/error"
+	"github.com/rkchv/chat/lib/logger"
+	"golang.org/x/exp/slog"
+
+	"github.com/rkchv/chat/internal/domain/chat"
+	"github.com/rkchv/chat/internal/domain/outbox"
+	"github.com/rkchv/chat/internal/services/models"
+)
+
+// PinMessage закрепляет сообщение в чате или снимает закрепление
+func (s *Service) PinMessage(ctx context.Context, req models.PinMessage) error {
+	denied, err := s.lockedChange(ctx, req.ChatId, req.UserId, func(ch *chat.Chat) error {
+		if !ch.Can(req.UserId, chat.ActionPinMessage) {
+			return chat.ErrPermissionDenied
+		}
+		if req.MessageId == 0 {
+			return nil
+		}
+
+		msg, err := s.getMessage(ctx, ch.Id, req.MessageId)
+		if err != nil {
+			return err
+		}
+		if msg.IsDeleted() {
+			return syserr.New("Сообщение не найдено", syserr.NotFound)
+		}
+
+		return nil
+	}, func(ctx context.Context, ch *chat.Chat) error {
+		if err := s.chatRepository.SetPinned(ctx, ch.Id, req.MessageId); err != nil {
+			return err
+		}
+
+		return s.addEvent(ctx, outbox.TopicMessages, outbox.MessagePinned, ch.Id, pinPayload{ChatId: ch.Id, MessageId: req.MessageId, UserId: req.UserId, At: time.Now()})
+	})
+	if err != nil {
+		logger.GetLogger(ctx).Error("failed to pin message", slog.String("error", err.Error()), slog.Int64("chatId", req.ChatId))
+		return err
+	}
+
+	return chatError(denied)
+}
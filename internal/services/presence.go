@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	syserr "github.com/rkchv/chat/lib/error"
+	"github.com/rkchv/chat/lib/logger"
+
+	"github.com/rkchv/chat/internal/domain/presence"
+)
+
+// Online отмечает пользователя в сети, вызывается при подключении и периодически, пока подключение живо
+func (s *Service) Online(ctx context.Context, userId int64) error {
+	err := s.presence.Touch(ctx, userId)
+	if err != nil {
+		logger.GetLogger(ctx).Error("failed to touch presence", slog.String("error", err.Error()), slog.Int64("userId", userId))
+	}
+
+	return err
+}
+
+// Offline снимает отметку этого экземпляра, когда в нем у пользователя не осталось подключений.
+// Подключения в других экземплярах держат свои отметки, и пользователь остается в сети
+func (s *Service) Offline(ctx context.Context, userId int64) error {
+	err := s.presence.Offline(ctx, userId)
+	if err != nil {
+		logger.GetLogger(ctx).Error("failed to set offline", slog.String("error", err.Error()), slog.Int64("userId", userId))
+	}
+
+	return err
+}
+
+// GetPresence в сети ли пользователи. Присутствие видно только тем, с кем есть общий чат, остальные пользователи
+// в ответ не попадают, чтобы по нему нельзя было следить за посторонними
+func (s *Service) GetPresence(ctx context.Context, userId int64, userIds []int64) ([]presence.Presence, error) {
+	userIds = slices.Clone(userIds)
+	slices.Sort(userIds)
+	userIds = slices.Compact(userIds)
+	if len(userIds) == 0 {
+		return nil, syserr.New("Не заданы пользователи", syserr.InvalidArgument)
+	}
+	if len(userIds) > presence.MaxUsers {
+		return nil, syserr.New("Слишком много пользователей в запросе", syserr.InvalidArgument)
+	}
+
+	contacts, err := s.chatRepository.Contacts(ctx, userId, userIds)
+	if err != nil {
+		logger.GetLogger(ctx).Error("failed to get contacts", slog.String("error", err.Error()), slog.Int64("userId", userId))
+		return nil, err
+	}
+	// свое присутствие пользователь видит, даже если он ни в одном чате
+	if _, self := slices.BinarySearch(userIds, userId); self && !slices.Contains(contacts, userId) {
+		contacts = append(contacts, userId)
+	}
+	if len(contacts) == 0 {
+		return nil, nil
+	}
+	slices.Sort(contacts)
+
+	result, err := s.presence.Get(ctx, contacts)
+	if err != nil {
+		logger.GetLogger(ctx).Error("failed to get presence", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return result, nil
+}
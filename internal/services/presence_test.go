@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/rkchv/chat/internal/domain/chat"
+	"github.com/rkchv/chat/internal/domain/presence"
+	"github.com/rkchv/chat/internal/repository"
+)
+
+// fakePresence все запрошенные пользователи в сети, запросы запоминаются
+type fakePresence struct {
+	repository.PresenceRepository
+	requested [][]int64
+}
+
+func (f *fakePresence) Get(_ context.Context, userIds []int64) ([]presence.Presence, error) {
+	f.requested = append(f.requested, userIds)
+
+	res := make([]presence.Presence, 0, len(userIds))
+	for _, id := range userIds {
+		res = append(res, presence.Presence{UserId: id, Online: true})
+	}
+
+	return res, nil
+}
+
+func TestGetPresenceOnlyContacts(t *testing.T) {
+	chats := newFakeChats(
+		chat.Chat{Id: 1, Members: []chat.Member{{UserId: 10}, {UserId: 20}}},
+		chat.Chat{Id: 2, Members: []chat.Member{{UserId: 10}, {UserId: 30}}},
+		chat.Chat{Id: 3, Members: []chat.Member{{UserId: 40}, {UserId: 50}}},
+	)
+
+	tests := []struct {
+		name    string
+		userId  int64
+		userIds []int64
+		want    []int64
+	}{
+		{name: "собеседники из разных чатов", userId: 10, userIds: []int64{30, 20, 20}, want: []int64{20, 30}},
+		{name: "посторонние не видны", userId: 10, userIds: []int64{20, 40, 50}, want: []int64{20}},
+		{name: "только посторонние", userId: 10, userIds: []int64{40}},
+		{name: "себя видно и без чатов", userId: 60, userIds: []int64{60, 10}, want: []int64{60}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakePresence{}
+			s := &Service{chatRepository: chats, presence: store}
+
+			users, err := s.GetPresence(testContext(), tt.userId, tt.userIds)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got []int64
+			for _, u := range users {
+				got = append(got, u.UserId)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("presence of %v, want %v", got, tt.want)
+			}
+			if len(tt.want) == 0 && len(store.requested) != 0 {
+				t.Fatalf("presence store was queried for %v", store.requested)
+			}
+		})
+	}
+}
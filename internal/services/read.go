@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/rkchv/chat/lib/logger"
+	"golang.org/x/exp/slog"
+
+	"github.com/rkchv/chat/internal/domain/chat"
+	"github.com/rkchv/chat/internal/services/models"
+)
+
+// MarkRead отмечает чат прочитанным до сообщения включительно и возвращает новую позицию, nil если она не сдвинулась.
+// Позиции прочтения меняются слишком часто, чтобы публиковать их во внешние системы, поэтому в outbox не пишутся
+func (s *Service) MarkRead(ctx context.Context, req models.MarkRead) (*chat.ReadPosition, error) {
+	if _, err := s.getForMember(ctx, req.ChatId, req.UserId); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getMessage(ctx, req.ChatId, req.MessageId); err != nil {
+		return nil, err
+	}
+
+	position := chat.ReadPosition{UserId: req.UserId, MessageId: req.MessageId, ReadAt: time.Now()}
+	moved, err := s.chatRepository.MarkRead(ctx, req.ChatId, position)
+	if err != nil {
+		logger.GetLogger(ctx).Error("failed to mark chat read", slog.String("error", err.Error()), slog.Any("request", req))
+		return nil, err
+	}
+
+	// упоминания в прочитанной части чата тоже прочитаны
+	if err = s.mentions.MarkRead(ctx, req.UserId, req.ChatId, req.MessageId); err != nil {
+		logger.GetLogger(ctx).Error("failed to mark mentions read", slog.String("error", err.Error()), slog.Any("request", req))
+		return nil, err
+	}
+
+	if !moved {
+		return nil, nil
+	}
+
+	return &position, nil
+}
+
+// SeenBy кто из участников, кроме автора, прочитал сообщение
+func (s *Service) SeenBy(ctx context.Context, chatId int64, messageId int64, userId int64) ([]chat.ReadPosition, error) {
+	if _, err := s.getForMember(ctx, chatId, userId); err != nil {
+		return nil, err
+	}
+
+	msg, err := s.getMessage(ctx, chatId, messageId)
+	if err != nil {
+		return nil, err
+	}
+
+	positions, err := s.chatRepository.ReadBy(ctx, chatId, messageId)
+	if err != nil {
+		logger.GetLogger(ctx).Error("failed to get message readers", slog.String("error", err.Error()), slog.Int64("messageId", messageId))
+		return nil, err
+	}
+
+	readers := make([]chat.ReadPosition, 0, len(positions))
+	for _, p := range positions {
+		if p.UserId != msg.UserId {
+			readers = append(readers, p)
+		}
+	}
+
+	return readers, nil
+}
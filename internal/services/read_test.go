@@ -0,0 +1,55 @@
+package services
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/rkchv/chat/internal/domain/chat"
+	"github.com/rkchv/chat/internal/domain/message"
+	"github.com/rkchv/chat/internal/services/models"
+)
+
+func TestMarkReadMonotonic(t *testing.T) {
+	chats := newFakeChats(chat.Chat{Id: 1, Members: []chat.Member{{UserId: 10, Role: chat.RoleMember}}})
+	mentions := &fakeMentions{}
+	s := &Service{
+		chatRepository:    chats,
+		messageRepository: &fakeMessages{messages: []*message.Message{{Id: 5, ChatId: 1}, {Id: 7, ChatId: 1}}},
+		mentions:          mentions,
+	}
+
+	tests := []struct {
+		name      string
+		messageId int64
+		// moved позицию вернули, чтобы разослать другим участникам
+		moved bool
+	}{
+		{name: "первое прочтение", messageId: 5, moved: true},
+		{name: "дальше по чату", messageId: 7, moved: true},
+		{name: "запоздавший запрос с другого устройства", messageId: 5},
+		{name: "повтор того же сообщения", messageId: 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			position, err := s.MarkRead(testContext(), models.MarkRead{ChatId: 1, UserId: 10, MessageId: tt.messageId})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (position != nil) != tt.moved {
+				t.Fatalf("position = %+v, want moved = %v", position, tt.moved)
+			}
+			if position != nil && position.MessageId != tt.messageId {
+				t.Fatalf("position message = %d, want %d", position.MessageId, tt.messageId)
+			}
+		})
+	}
+
+	if got := chats.read[[2]int64{1, 10}]; got != 7 {
+		t.Fatalf("read position = %d, want 7", got)
+	}
+	// упоминания отмечаются и при несдвинувшейся позиции, отметка идемпотентна
+	if !slices.Equal(mentions.readUpTo, []int64{5, 7, 5, 7}) {
+		t.Fatalf("mentions read up to %v", mentions.readUpTo)
+	}
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/rkchv/chat/lib/logger"
+	"golang.org/x/exp/slog"
+
+	"github.com/rkchv/chat/internal/domain/chat"
+	"github.com/rkchv/chat/internal/domain/outbox"
+	"github.com/rkchv/chat/internal/services/models"
+)
+
+// SetMemberRole меняет роль участника чата
+func (s *Service) SetMemberRole(ctx context.Context, req models.SetMemberRole) error {
+	return s.changeRoles(ctx, req.ChatId, req.UserId, func(ch *chat.Chat) ([]int64, error) {
+		return []int64{req.MemberId}, ch.SetRole(req.UserId, req.MemberId, req.Role)
+	})
+}
+
+// TransferOwnership передает владение чатом, прежний владелец становится администратором
+func (s *Service) TransferOwnership(ctx context.Context, req models.TransferOwnership) error {
+	return s.changeRoles(ctx, req.ChatId, req.UserId, func(ch *chat.Chat) ([]int64, error) {
+		return []int64{req.UserId, req.NewOwnerId}, ch.TransferOwnership(req.UserId, req.NewOwnerId)
+	})
+}
+
+// changeRoles применяет change к чату и сохраняет роли участников, которых оно затронуло, вместе с событиями.
+// Права проверяются по заблокированным участникам: иначе две параллельные передачи владения прошли бы проверку
+// по одному и тому же снимку и в чате оказалось бы два владельца
+func (s *Service) changeRoles(ctx context.Context, chatId int64, by int64, change func(ch *chat.Chat) ([]int64, error)) error {
+	var userIds []int64
+	denied, err := s.lockedChange(ctx, chatId, by, func(ch *chat.Chat) error {
+		var err error
+		userIds, err = change(ch)
+		return err
+	}, func(ctx context.Context, ch *chat.Chat) error {
+		now := time.Now()
+		for _, userId := range userIds {
+			m, _ := ch.Member(userId)
+			if err := s.chatRepository.SetRole(ctx, ch.Id, userId, m.Role); err != nil {
+				return err
+			}
+
+			err := s.addEvent(ctx, outbox.TopicMembers, outbox.MemberRole, ch.Id, memberPayload{ChatId: ch.Id, UserId: userId, By: by, Role: string(m.Role), At: now})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		logger.GetLogger(ctx).Error("failed to save member roles", slog.String("error", err.Error()), slog.Int64("chatId", chatId))
+		return err
+	}
+
+	return chatError(denied)
+}
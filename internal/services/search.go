@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	syserr "github.com/rkchv/chat/lib/error"
+	"github.com/rkchv/chat/lib/logger"
+	"golang.org/x/exp/slog"
+
+	"github.com/rkchv/chat/internal/repository"
+	"github.com/rkchv/chat/internal/services/models"
+)
+
+const maxSearchQueryLen = 256
+
+// SearchMessages ищет сообщения по тексту во всех чатах пользователя или в одном из них, от новых к старым
+func (s *Service) SearchMessages(ctx context.Context, req models.SearchMessages) (*models.SearchPage, error) {
+	query := strings.TrimSpace(req.Query)
+	if query == "" {
+		return nil, syserr.New("Пустой поисковый запрос", syserr.InvalidArgument)
+	}
+	if utf8.RuneCountInString(query) > maxSearchQueryLen {
+		return nil, syserr.New("Слишком длинный поисковый запрос", syserr.InvalidArgument)
+	}
+
+	after, err := decodeCursor(req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ChatId > 0 {
+		if _, err = s.getForMember(ctx, req.ChatId, req.UserId); err != nil {
+			return nil, err
+		}
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+	limit = min(limit, maxPageSize)
+
+	hits, err := s.messageRepository.Search(ctx, repository.SearchFilter{
+		UserId: req.UserId,
+		Query:  query,
+		ChatId: req.ChatId,
+		After:  after,
+		Limit:  limit,
+	})
+	if err != nil {
+		logger.GetLogger(ctx).Error("failed to search messages", slog.String("error", err.Error()), slog.Any("request", req))
+		return nil, err
+	}
+
+	page := &models.SearchPage{Hits: hits}
+	if uint64(len(hits)) == limit {
+		last := hits[len(hits)-1].Message
+		page.NextCursor = encodeCursor(repository.MessageKey{CreatedAt: last.CreatedAt, Id: last.Id})
+	}
+
+	return page, nil
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	syserr "github.com/rkchv/chat/lib/error"
+	"github.com/rkchv/chat/lib/logger"
+	"golang.org/x/exp/slog"
+
+	"github.com/rkchv/chat/internal/domain/message"
+	"github.com/rkchv/chat/internal/domain/outbox"
+	"github.com/rkchv/chat/internal/services/models"
+)
+
+// SendMessage сохраняет сообщение в чат, рассылкой по подключенным клиентам занимается вызывающая сторона
+func (s *Service) SendMessage(ctx context.Context, req models.SendMessage) (*message.Message, []int64, error) {
+	log := logger.GetLogger(ctx)
+	attachmentIds := slices.Clone(req.AttachmentIds)
+	slices.Sort(attachmentIds)
+	attachmentIds = slices.Compact(attachmentIds)
+	if strings.TrimSpace(req.Text) == "" && len(attachmentIds) == 0 {
+		return nil, nil, syserr.New("Пустое сообщение", syserr.InvalidArgument)
+	}
+	if len(attachmentIds) > message.MaxAttachments {
+		return nil, nil, attachmentError(message.ErrTooManyAttachments)
+	}
+
+	ch, err := s.getForMember(ctx, req.ChatId, req.UserId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = ch.CanSend(req.UserId, time.Now()); err != nil {
+		return nil, nil, chatError(err)
+	}
+
+	msg := message.NewMessage(req.ChatId, req.UserId, req.Text)
+	var recipients, mentioned []int64
+	msg.Mentions, recipients = ch.Mentions(req.UserId, req.Text)
+	err = s.txManager.ReadCommitted(ctx, func(ctx context.Context) error {
+		if err := s.messageRepository.Save(ctx, &msg); err != nil {
+			return err
+		}
+
+		if err := s.attach(ctx, &msg, attachmentIds); err != nil {
+			return err
+		}
+
+		var err error
+		mentioned, err = s.mentions.Add(ctx, &msg, recipients)
+		if err != nil {
+			return err
+		}
+
+		payload := newMessagePayload(&msg)
+		payload.Mentioned = mentioned
+
+		return s.addEvent(ctx, outbox.TopicMessages, outbox.MessageCreated, msg.ChatId, payload)
+	})
+	if err != nil {
+		log.Error("failed to save message", slog.String("error", err.Error()), slog.Int64("chatId", req.ChatId))
+		return nil, nil, err
+	}
+
+	return &msg, mentioned, nil
+}
@@ -2,31 +2,98 @@ package services
 
 import (
 	"context"
+	"io"
+
+	"github.com/rkchv/chat/lib/blob"
+	"github.com/rkchv/chat/lib/db"
 
 	"github.com/rkchv/chat/internal/domain/chat"
+	"github.com/rkchv/chat/internal/domain/invite"
+	"github.com/rkchv/chat/internal/domain/message"
+	"github.com/rkchv/chat/internal/domain/presence"
 	"github.com/rkchv/chat/internal/repository"
 	"github.com/rkchv/chat/internal/services/models"
 )
 
 type ChatService interface {
-	Create(ctx context.Context) (int64, error)
+	Create(ctx context.Context, req models.Create) (*chat.Chat, error)
 	Connect(ctx context.Context, req models.Connect) error
+	Typing(ctx context.Context, req models.Connect) error
+	AddMembers(ctx context.Context, req models.AddMembers) ([]int64, error)
+	RemoveMember(ctx context.Context, req models.RemoveMember) error
+	LeaveChat(ctx context.Context, chatId int64, userId int64) error
+	ListMembers(ctx context.Context, chatId int64, userId int64) ([]chat.Member, error)
+	SetMemberRole(ctx context.Context, req models.SetMemberRole) error
+	TransferOwnership(ctx context.Context, req models.TransferOwnership) error
+	PinMessage(ctx context.Context, req models.PinMessage) error
+	CreateInvite(ctx context.Context, req models.CreateInvite) (*invite.Invite, string, error)
+	RevokeInvite(ctx context.Context, inviteId int64, userId int64) error
+	JoinByInvite(ctx context.Context, req models.JoinByInvite) (*chat.Chat, bool, error)
+	BanMember(ctx context.Context, req models.BanMember) (bool, error)
+	UnbanMember(ctx context.Context, chatId int64, userId int64, memberId int64) error
+	MuteMember(ctx context.Context, req models.MuteMember) error
+	UnmuteMember(ctx context.Context, chatId int64, userId int64, memberId int64) error
 	Delete(ctx context.Context, chatId int64) error
 	Get(ctx context.Context, chatId int64) (chat.Chat, error)
-}
-
-type AuthServiceClient interface {
-	CanDelete(ctx context.Context, userID int64) (bool, error)
+	// SendMessage возвращает сообщение и упомянутых в нем участников
+	SendMessage(ctx context.Context, req models.SendMessage) (*message.Message, []int64, error)
+	UploadAttachment(ctx context.Context, req models.UploadAttachment) (*message.Attachment, error)
+	OpenAttachment(ctx context.Context, attachmentId int64, userId int64) (*message.Attachment, io.ReadCloser, error)
+	History(ctx context.Context, req models.History, page func([]*message.Message) error) error
+	ListMessages(ctx context.Context, req models.ListMessages) (*models.MessagesPage, error)
+	ListChats(ctx context.Context, req models.ListChats) (*models.ChatsPage, error)
+	SearchMessages(ctx context.Context, req models.SearchMessages) (*models.SearchPage, error)
+	ListMentions(ctx context.Context, req models.ListMentions) (*models.MentionsPage, error)
+	MarkRead(ctx context.Context, req models.MarkRead) (*chat.ReadPosition, error)
+	SeenBy(ctx context.Context, chatId int64, messageId int64, userId int64) ([]chat.ReadPosition, error)
+	// EditMessage возвращает сообщение и участников, которых в нем упомянули впервые
+	EditMessage(ctx context.Context, req models.EditMessage) (*message.Message, []int64, error)
+	DeleteMessage(ctx context.Context, req models.DeleteMessage) (*message.Message, error)
+	RemoveUser(ctx context.Context, userId int64) (*models.RemovedUser, error)
+	RenameUser(ctx context.Context, userId int64, name string) error
+	Online(ctx context.Context, userId int64) error
+	Offline(ctx context.Context, userId int64) error
+	GetPresence(ctx context.Context, userId int64, userIds []int64) ([]presence.Presence, error)
 }
 
 type Service struct {
-	chatRepository repository.Repository
-	authService    AuthServiceClient
+	txManager         db.Transactor
+	chatRepository    repository.Repository
+	messageRepository repository.MessageRepository
+	outboxRepository  repository.OutboxRepository
+	inviteRepository  repository.InviteRepository
+	presence          repository.PresenceRepository
+	attachments       repository.AttachmentRepository
+	mentions          repository.MentionRepository
+	blobs             blob.Store
+	inviteSigner      *invite.Signer
+	attachmentPolicy  message.AttachmentPolicy
 }
 
-func NewService(chatRepository repository.Repository, authClient AuthServiceClient) *Service {
+func NewService(
+	txManager db.Transactor,
+	chatRepository repository.Repository,
+	messageRepository repository.MessageRepository,
+	outboxRepository repository.OutboxRepository,
+	inviteRepository repository.InviteRepository,
+	presenceRepository repository.PresenceRepository,
+	attachmentRepository repository.AttachmentRepository,
+	mentionRepository repository.MentionRepository,
+	blobs blob.Store,
+	inviteSigner *invite.Signer,
+	attachmentPolicy message.AttachmentPolicy,
+) *Service {
 	return &Service{
-		chatRepository: chatRepository,
-		authService:    authClient,
+		txManager:         txManager,
+		chatRepository:    chatRepository,
+		messageRepository: messageRepository,
+		outboxRepository:  outboxRepository,
+		inviteRepository:  inviteRepository,
+		presence:          presenceRepository,
+		attachments:       attachmentRepository,
+		mentions:          mentionRepository,
+		blobs:             blobs,
+		inviteSigner:      inviteSigner,
+		attachmentPolicy:  attachmentPolicy,
 	}
 }
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/rkchv/chat/lib/logger"
+	"golang.org/x/exp/slog"
+
+	"github.com/rkchv/chat/internal/domain/chat"
+	"github.com/rkchv/chat/internal/domain/outbox"
+	"github.com/rkchv/chat/internal/repository"
+	"github.com/rkchv/chat/internal/services/models"
+)
+
+// RemoveUser исключает удаленного пользователя из всех чатов, возвращает чаты, где он состоял.
+// Чаты, которыми он владел, в той же транзакции переходят к старшему из оставшихся участников
+func (s *Service) RemoveUser(ctx context.Context, userId int64) (*models.RemovedUser, error) {
+	log := logger.GetLogger(ctx)
+	var removed *models.RemovedUser
+	err := s.txManager.ReadCommitted(ctx, func(ctx context.Context) error {
+		memberships, err := s.chatRepository.RemoveUser(ctx, userId)
+		if err != nil {
+			return err
+		}
+		// события блокируют счетчики outbox своих чатов, порядок по id чата не дает двум удалениям
+		// заблокировать друг друга
+		slices.SortFunc(memberships, func(a, b repository.Membership) int { return cmp.Compare(a.ChatId, b.ChatId) })
+
+		now := time.Now()
+		removed = &models.RemovedUser{ChatIds: make([]int64, 0, len(memberships)), Owners: make(map[int64]int64)}
+		for _, m := range memberships {
+			removed.ChatIds = append(removed.ChatIds, m.ChatId)
+			err = s.addEvent(ctx, outbox.TopicMembers, outbox.MemberRemoved, m.ChatId, memberPayload{ChatId: m.ChatId, UserId: userId, At: now})
+			if err != nil {
+				return err
+			}
+
+			if m.Role != chat.RoleOwner {
+				continue
+			}
+
+			ownerId, ok, err := s.chatRepository.PromoteOwner(ctx, m.ChatId)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			removed.Owners[m.ChatId] = ownerId
+
+			err = s.addEvent(ctx, outbox.TopicMembers, outbox.MemberRole, m.ChatId, memberPayload{ChatId: m.ChatId, UserId: ownerId, Role: string(chat.RoleOwner), At: now})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to remove user from chats", slog.String("error", err.Error()), slog.Int64("userId", userId))
+		return nil, err
+	}
+
+	return removed, nil
+}
+
+// RenameUser обновляет отображаемое имя пользователя в чатах
+func (s *Service) RenameUser(ctx context.Context, userId int64, name string) error {
+	err := s.chatRepository.RenameUser(ctx, userId, name)
+	if err != nil {
+		logger.GetLogger(ctx).Error("failed to rename user", slog.String("error", err.Error()), slog.Int64("userId", userId))
+	}
+
+	return err
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/rkchv/chat/internal/domain/chat"
+	"github.com/rkchv/chat/internal/domain/outbox"
+)
+
+func TestRemoveUserPromotesOwners(t *testing.T) {
+	chats := newFakeChats(
+		chat.Chat{Id: 1, Members: []chat.Member{{UserId: 10, Role: chat.RoleOwner}, {UserId: 20, Role: chat.RoleMember}}},
+		chat.Chat{Id: 2, Members: []chat.Member{{UserId: 30, Role: chat.RoleOwner}, {UserId: 10, Role: chat.RoleMember}}},
+		chat.Chat{Id: 3, Members: []chat.Member{{UserId: 10, Role: chat.RoleOwner}}},
+		chat.Chat{Id: 4, Members: []chat.Member{{UserId: 40, Role: chat.RoleOwner}}},
+	)
+	events := &fakeOutbox{}
+	s := &Service{txManager: fakeTx{}, chatRepository: chats, outboxRepository: events}
+
+	removed, err := s.RemoveUser(testContext(), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(removed.ChatIds, []int64{1, 2, 3}) {
+		t.Fatalf("chat ids = %v, want [1 2 3]", removed.ChatIds)
+	}
+	// в чате 3 никого не осталось, в чате 2 пользователь не был владельцем
+	if !reflect.DeepEqual(removed.Owners, map[int64]int64{1: 20}) {
+		t.Fatalf("owners = %v, want chat 1 owned by 20", removed.Owners)
+	}
+
+	var types []string
+	for _, event := range events.events {
+		types = append(types, event.Type)
+	}
+	want := []string{outbox.MemberRemoved, outbox.MemberRole, outbox.MemberRemoved, outbox.MemberRemoved}
+	if !slices.Equal(types, want) {
+		t.Fatalf("events = %v, want %v", types, want)
+	}
+}
@@ -0,0 +1,23 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotFound объекта с таким ключом нет в хранилище
+var ErrNotFound = errors.New("blob: not found")
+
+// Store хранилище двоичных объектов (файлов вложений)
+type Store interface {
+	// Put сохраняет содержимое r целиком и возвращает ключ объекта и его размер.
+	// Если чтение r завершится ошибкой, ничего не сохраняется
+	Put(ctx context.Context, r io.Reader) (key string, size int64, err error)
+	// Open открывает объект на чтение, вызывающий должен закрыть его
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete удаляет объект, если его последний раз записывали раньше before. Повторный Put того же содержимого
+	// обновляет время записи, так что только что загруженный заново объект не удаляется. Объекта нет - не ошибка
+	Delete(ctx context.Context, key string, before time.Time) error
+}
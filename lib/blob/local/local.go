@@ -0,0 +1,146 @@
+package local
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rkchv/chat/lib/blob"
+)
+
+var _ blob.Store = (*Store)(nil)
+
+// Store хранилище в локальной файловой системе с адресацией по содержимому: ключ объекта - sha256 его содержимого.
+// Одинаковые файлы хранятся один раз, записанный объект больше не меняется
+type Store struct {
+	dir string
+}
+
+// NewStore Создает хранилище в каталоге dir, каталог создается при необходимости
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	return &Store{dir: dir}, nil
+}
+
+// Put пишет содержимое во временный файл, считая хеш, и затем атомарно переносит его на место по ключу
+func (s *Store) Put(ctx context.Context, r io.Reader) (string, int64, error) {
+	tmp, err := os.CreateTemp(s.dir, "upload-*")
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() {
+		// после успешного переноса файла уже нет, ошибку удаления игнорируем
+		_ = os.Remove(tmp.Name())
+	}()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), contextReader{ctx: ctx, r: r})
+	if err != nil {
+		_ = tmp.Close()
+		return "", 0, err
+	}
+
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return "", 0, err
+	}
+	if err = tmp.Close(); err != nil {
+		return "", 0, err
+	}
+
+	key := hex.EncodeToString(hash.Sum(nil))
+	path := s.path(key)
+	if err = os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return "", 0, err
+	}
+
+	// такой объект уже есть - содержимое то же самое, переписывать не нужно. Время записи обновляем,
+	// чтобы Delete не удалил объект, который снова понадобился
+	if _, err = os.Stat(path); err == nil {
+		now := time.Now()
+		if err = os.Chtimes(path, now, now); err != nil {
+			return "", 0, err
+		}
+		return key, size, nil
+	}
+
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return "", 0, err
+	}
+
+	return key, size, nil
+}
+
+func (s *Store) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	if !validKey(key) {
+		return nil, blob.ErrNotFound
+	}
+
+	f, err := os.Open(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, blob.ErrNotFound
+	}
+
+	return f, err
+}
+
+func (s *Store) Delete(_ context.Context, key string, before time.Time) error {
+	if !validKey(key) {
+		return nil
+	}
+
+	path := s.path(key)
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !info.ModTime().Before(before) {
+		return nil
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// path раскладывает объекты по подкаталогам по первым байтам ключа, чтобы не держать все файлы в одном каталоге
+func (s *Store) path(key string) string {
+	return filepath.Join(s.dir, key[:2], key[2:4], key)
+}
+
+// validKey ключ - hex sha256, так что из него нельзя собрать путь за пределы каталога хранилища
+func validKey(key string) bool {
+	if len(key) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(key)
+	return err == nil
+}
+
+// contextReader прерывает чтение, когда контекст отменен
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return r.r.Read(p)
+}
@@ -0,0 +1,91 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rkchv/chat/lib/blob"
+)
+
+func TestStoreDelete(t *testing.T) {
+	tests := []struct {
+		name string
+		// before сдвиг границы относительно момента записи
+		before  time.Duration
+		deleted bool
+	}{
+		{name: "записан раньше границы", before: time.Hour, deleted: true},
+		{name: "записан позже границы", before: -time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s, err := NewStore(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			key, _, err := s.Put(ctx, strings.NewReader("content"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err = s.Delete(ctx, key, time.Now().Add(tt.before)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			_, err = s.Open(ctx, key)
+			if deleted := errors.Is(err, blob.ErrNotFound); deleted != tt.deleted {
+				t.Fatalf("deleted = %v, want %v", deleted, tt.deleted)
+			}
+		})
+	}
+}
+
+func TestStorePutRefreshesExisting(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, _, err := s.Put(ctx, strings.NewReader("content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// файл загрузили давно и не прикрепили
+	old := time.Now().Add(-2 * time.Hour)
+	if err = os.Chtimes(s.path(key), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	// тот же файл загрузили снова, пока удалялась старая загрузка
+	if _, _, err = s.Put(ctx, strings.NewReader("content")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = s.Delete(ctx, key, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err = s.Open(ctx, key); err != nil {
+		t.Fatalf("reused content was deleted: %v", err)
+	}
+}
+
+func TestStoreDeleteMissing(t *testing.T) {
+	s, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{strings.Repeat("a", 64), "../../etc/passwd"} {
+		if err = s.Delete(context.Background(), key, time.Now()); err != nil {
+			t.Fatalf("Delete(%q) = %v, want nil", key, err)
+		}
+	}
+}
@@ -96,6 +96,11 @@ func (p *pg) ReadCommitted(ctx context.Context, f db.Handler) error {
 
 // transaction основная функция, которая выполняет указанный пользователем обработчик в транзакции
 func (p *pg) transaction(ctx context.Context, opts pgx.TxOptions, fn db.Handler) (err error) {
+	// Если транзакция уже идет, выполняемся в ней, фиксировать или откатывать ее будет внешний вызов.
+	if _, ok := ctx.Value(TxCtxKey).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
 	// Стартуем новую транзакцию.
 	tx, err := p.BeginTx(ctx, opts)
 	if err != nil {
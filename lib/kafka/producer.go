@@ -0,0 +1,57 @@
+package kafka
+
+import (
+	"github.com/IBM/sarama"
+)
+
+// Message сообщение для отправки в kafka
+type Message struct {
+	Topic   string
+	Key     string
+	Value   []byte
+	Headers map[string]string
+}
+
+// Producer синхронный отправитель сообщений
+type Producer interface {
+	// Send отправляет пачку сообщений и дожидается подтверждения брокеров
+	Send(messages ...Message) error
+	Close() error
+}
+
+type producer struct {
+	producer sarama.SyncProducer
+}
+
+// NewProducer новый экземпляр. В конфиге обязательно должен быть включен Producer.Return.Successes
+func NewProducer(brokers []string, config *sarama.Config) (Producer, error) {
+	prod, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &producer{producer: prod}, nil
+}
+
+func (p *producer) Send(messages ...Message) error {
+	msgs := make([]*sarama.ProducerMessage, 0, len(messages))
+	for _, m := range messages {
+		headers := make([]sarama.RecordHeader, 0, len(m.Headers))
+		for k, v := range m.Headers {
+			headers = append(headers, sarama.RecordHeader{Key: []byte(k), Value: []byte(v)})
+		}
+
+		msgs = append(msgs, &sarama.ProducerMessage{
+			Topic:   m.Topic,
+			Key:     sarama.StringEncoder(m.Key),
+			Value:   sarama.ByteEncoder(m.Value),
+			Headers: headers,
+		})
+	}
+
+	return p.producer.SendMessages(msgs)
+}
+
+func (p *producer) Close() error {
+	return p.producer.Close()
+}
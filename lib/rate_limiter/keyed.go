@@ -0,0 +1,91 @@
+package rate_limiter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter ограничивает частоту запросов отдельно для каждого ключа, например id пользователя
+type Limiter interface {
+	// Allow разрешен ли запрос по ключу, если нет - через сколько можно повторить
+	Allow(ctx context.Context, key string) (bool, time.Duration, error)
+}
+
+var _ Limiter = (*KeyedLimiter)(nil)
+
+// KeyedLimiter держит в памяти по корзине на ключ. Корзины, которыми давно не пользовались, удаляются
+type KeyedLimiter struct {
+	maxTokens uint
+	period    time.Duration
+	// idleTTL через сколько простоя корзина удаляется
+	idleTTL time.Duration
+
+	m       sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	limiter  *RateLimiter
+	lastUsed time.Time
+}
+
+// NewKeyed Создает ограничитель с корзиной на maxTokens запросов за period для каждого ключа.
+// idleTTL не бывает меньше period: раньше корзина может быть еще не полной, и ее удаление сбросило бы лимит
+func NewKeyed(maxTokens uint, period time.Duration, idleTTL time.Duration) *KeyedLimiter {
+	return &KeyedLimiter{
+		maxTokens: maxTokens,
+		period:    period,
+		idleTTL:   max(idleTTL, period),
+		buckets:   make(map[string]*bucket),
+	}
+}
+
+func (k *KeyedLimiter) Allow(_ context.Context, key string) (bool, time.Duration, error) {
+	now := time.Now()
+
+	k.m.Lock()
+	b, ok := k.buckets[key]
+	if !ok {
+		b = &bucket{limiter: New(k.maxTokens, k.period)}
+		k.buckets[key] = b
+	}
+	b.lastUsed = now
+	k.m.Unlock()
+
+	allowed, retryAfter := b.limiter.Take(now)
+
+	return allowed, retryAfter, nil
+}
+
+// Evict удаляет корзины, которыми не пользовались дольше idleTTL, возвращает сколько удалено.
+// Простоявшая дольше периода корзина все равно была бы полной, так что удаление не меняет лимитов
+func (k *KeyedLimiter) Evict(now time.Time) int {
+	k.m.Lock()
+	defer k.m.Unlock()
+
+	evicted := 0
+	for key, b := range k.buckets {
+		if now.Sub(b.lastUsed) > k.idleTTL {
+			delete(k.buckets, key)
+			evicted++
+		}
+	}
+
+	return evicted
+}
+
+// Run периодически удаляет простаивающие корзины, пока не отменен контекст
+func (k *KeyedLimiter) Run(ctx context.Context) {
+	ticker := time.NewTicker(k.idleTTL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			k.Evict(now)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package rate_limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestKeyedLimiterAllow(t *testing.T) {
+	ctx := context.Background()
+	k := NewKeyed(2, time.Hour, time.Hour)
+
+	tests := []struct {
+		name    string
+		key     string
+		allowed bool
+	}{
+		{name: "первый запрос", key: "1", allowed: true},
+		{name: "второй запрос", key: "1", allowed: true},
+		{name: "корзина пуста", key: "1", allowed: false},
+		{name: "у другого ключа своя корзина", key: "2", allowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, retryAfter, err := k.Allow(ctx, tt.key)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if allowed != tt.allowed {
+				t.Fatalf("allowed = %v, want %v", allowed, tt.allowed)
+			}
+			if allowed && retryAfter != 0 {
+				t.Fatalf("retryAfter = %v for allowed request", retryAfter)
+			}
+			if !allowed && (retryAfter <= 0 || retryAfter > time.Hour) {
+				t.Fatalf("retryAfter = %v, want within (0, 1h]", retryAfter)
+			}
+		})
+	}
+}
+
+func TestKeyedLimiterEvict(t *testing.T) {
+	tests := []struct {
+		name    string
+		idleTTL time.Duration
+		idle    time.Duration
+		evicted int
+	}{
+		{name: "корзина еще используется", idleTTL: 2 * time.Minute, idle: time.Minute, evicted: 0},
+		{name: "корзина простаивает дольше idleTTL", idleTTL: 2 * time.Minute, idle: 3 * time.Minute, evicted: 1},
+		{name: "idleTTL меньше периода поднимается до периода", idleTTL: time.Second, idle: 30 * time.Second, evicted: 0},
+		{name: "после периода корзину можно удалить", idleTTL: time.Second, idle: 2 * time.Minute, evicted: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := NewKeyed(1, time.Minute, tt.idleTTL)
+			if _, _, err := k.Allow(context.Background(), "1"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if evicted := k.Evict(time.Now().Add(tt.idle)); evicted != tt.evicted {
+				t.Fatalf("evicted = %d, want %d", evicted, tt.evicted)
+			}
+		})
+	}
+}
+
+func TestKeyedLimiterEvictKeepsLimit(t *testing.T) {
+	ctx := context.Background()
+	k := NewKeyed(1, time.Hour, time.Millisecond)
+
+	if allowed, _, _ := k.Allow(ctx, "1"); !allowed {
+		t.Fatal("first request must be allowed")
+	}
+	k.Evict(time.Now().Add(time.Second))
+
+	// удаление пустой корзины раньше периода вернуло бы токен
+	if allowed, _, _ := k.Allow(ctx, "1"); allowed {
+		t.Fatal("eviction must not reset the limit before the period")
+	}
+}
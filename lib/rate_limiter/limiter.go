@@ -1,7 +1,7 @@
 package rate_limiter
 
 import (
-	"sync/atomic"
+	"sync"
 	"time"
 )
 
@@ -12,52 +12,49 @@ type RateLimiter struct {
 	// period интервал времени для которого работает ограничение
 	// например для 1 RPS period будет time.Second
 	period time.Duration
+
+	m sync.Mutex
 	// bucketTokens корзина с токенами, в нее идет пополнение не более чем maxTokens
-	bucketTokens atomic.Uint32
+	bucketTokens uint32
 	// lastFill когда было последнее заполнение (генерация) корзины токенами
 	lastFill time.Time
 }
 
 // New Создает новый экземпляр RateLimiter
 func New(maxTokens uint, period time.Duration) *RateLimiter {
-	rl := &RateLimiter{
-		lastFill:  time.Now(),
-		period:    period,
-		maxTokens: uint32(maxTokens),
+	return &RateLimiter{
+		lastFill:     time.Now(),
+		period:       period,
+		maxTokens:    uint32(maxTokens),
+		bucketTokens: uint32(maxTokens),
 	}
-
-	rl.bucketTokens.Store(uint32(maxTokens))
-
-	return rl
 }
 
 // Allow проверяет есть ли в корзине еще токены (не превышена ли скорость запросов)
 // так же непосредственно при вызове производит дозаполнение корзины
 func (r *RateLimiter) Allow() bool {
-	// Считаем сколько токенов должны были сгенерировать за время прошедшее с последнего заполнения корзины(генерации)
-	sinceRefill := uint32(time.Since(r.lastFill) / r.period) //сколько прошло заданных "периодов"
-	tokensToAdd := r.maxTokens * sinceRefill                 //кол-во токенов которое должно получится за эти периоды
-	currentTokens := r.bucketTokens.Load() + tokensToAdd     //общий остаток токенов
-	// Если недостаточно, запросы пришли чаще
-	if currentTokens < 1 {
-		return false
+	ok, _ := r.Take(time.Now())
+	return ok
+}
+
+// Take берет токен из корзины на момент now. Если токенов нет, возвращает через сколько корзина пополнится
+func (r *RateLimiter) Take(now time.Time) (bool, time.Duration) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	// за каждый прошедший период корзина заполняется целиком, поэтому после хотя бы одного периода она полная.
+	// Время заполнения сдвигаем на целое число периодов, а не ставим now - так мы как бы имитируем ticker
+	if sinceRefill := now.Sub(r.lastFill) / r.period; sinceRefill > 0 {
+		r.bucketTokens = r.maxTokens
+		r.lastFill = r.lastFill.Add(sinceRefill * r.period)
 	}
 
-	// Если токены забиты под завязку, время заполнения = текущее время
-	if currentTokens > r.maxTokens {
-		r.lastFill = time.Now()
-		r.bucketTokens.Store(r.maxTokens - 1)
-	} else {
-		//Если место в корзине есть, то кладем столько токенов, сколько сгенерировались за прошедший период
-		//один вычитаем для текущего запроса
-		//время последнего заполнения пишем не как time.Now()
-		//а как отношение кол-ва сгенер. токенов на заданный интервал
-		//мы как бы имитируем тем самым ticker
-		deltaRefills := tokensToAdd / r.maxTokens
-		deltaTime := time.Duration(deltaRefills) * r.period
-		r.lastFill = r.lastFill.Add(deltaTime)
-		r.bucketTokens.Store(currentTokens - 1)
+	// Если недостаточно, запросы пришли чаще
+	if r.bucketTokens < 1 {
+		return false, r.lastFill.Add(r.period).Sub(now)
 	}
 
-	return true
+	r.bucketTokens--
+
+	return true, 0
 }
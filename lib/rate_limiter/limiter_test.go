@@ -0,0 +1,37 @@
+package rate_limiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterTake(t *testing.T) {
+	tests := []struct {
+		name       string
+		after      time.Duration
+		allowed    bool
+		retryAfter time.Duration
+	}{
+		{name: "первый токен", after: 0, allowed: true},
+		{name: "второй токен", after: 100 * time.Millisecond, allowed: true},
+		{name: "корзина пуста", after: 400 * time.Millisecond, allowed: false, retryAfter: 600 * time.Millisecond},
+		{name: "корзина пополнилась через период", after: time.Second, allowed: true},
+		{name: "пополнение по границе периода, а не по времени запроса", after: 1500 * time.Millisecond, allowed: true},
+		{name: "до следующего периода токенов нет", after: 1900 * time.Millisecond, allowed: false, retryAfter: 100 * time.Millisecond},
+	}
+
+	r := New(2, time.Second)
+	start := r.lastFill
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, retryAfter := r.Take(start.Add(tt.after))
+			if allowed != tt.allowed {
+				t.Fatalf("allowed = %v, want %v", allowed, tt.allowed)
+			}
+			if retryAfter != tt.retryAfter {
+				t.Fatalf("retryAfter = %v, want %v", retryAfter, tt.retryAfter)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package rate_limiter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rkchv/chat/lib/redis"
+)
+
+// tokenBucketScript та же корзина с токенами, что и RateLimiter, но состояние хранится в редис.
+// Время берется у редиса, чтобы у всех экземпляров сервиса были одни часы.
+// KEYS[1] - ключ корзины, ARGV[1] - maxTokens, ARGV[2] - period в миллисекундах.
+// Возвращает {1, 0} если запрос разрешен, иначе {0, через сколько миллисекунд пополнится корзина}
+const tokenBucketScript = `
+local max = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'filled')
+local tokens = tonumber(state[1])
+local filled = tonumber(state[2])
+if tokens == nil or filled == nil then
+	tokens = max
+	filled = now
+end
+
+local periods = math.floor((now - filled) / period)
+if periods > 0 then
+	tokens = max
+	filled = filled + periods * period
+end
+
+local allowed = 0
+local retry = 0
+if tokens > 0 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = filled + period - now
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'filled', filled)
+-- простоявшая дольше периода корзина все равно была бы полной, поэтому ее можно забыть
+redis.call('PEXPIRE', KEYS[1], period * 2)
+
+return {allowed, retry}
+`
+
+var _ Limiter = (*RedisLimiter)(nil)
+
+// RedisLimiter корзины с токенами в редис, общие для всех экземпляров сервиса
+type RedisLimiter struct {
+	client    redis.Client
+	prefix    string
+	maxTokens uint
+	period    time.Duration
+}
+
+// NewRedis Создает ограничитель на maxTokens запросов за period для каждого ключа, ключи в редис начинаются с prefix
+func NewRedis(client redis.Client, prefix string, maxTokens uint, period time.Duration) *RedisLimiter {
+	return &RedisLimiter{
+		client:    client,
+		prefix:    prefix,
+		maxTokens: maxTokens,
+		period:    period,
+	}
+}
+
+func (r *RedisLimiter) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	res, err := r.client.Eval(ctx, tokenBucketScript, []string{r.prefix + key}, r.maxTokens, r.period.Milliseconds())
+	if err != nil {
+		return false, 0, err
+	}
+
+	values, ok := res.([]interface{})
+	if !ok || len(values) != 2 {
+		return false, 0, fmt.Errorf("unexpected rate limiter script result: %v", res)
+	}
+
+	allowed, okAllowed := values[0].(int64)
+	retry, okRetry := values[1].(int64)
+	if !okAllowed || !okRetry {
+		return false, 0, fmt.Errorf("unexpected rate limiter script result: %v", res)
+	}
+
+	return allowed == 1, time.Duration(retry) * time.Millisecond, nil
+}
@@ -2,13 +2,32 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+// ErrNil ключа нет
+var ErrNil = errors.New("redis: nil")
+
+// MessageHandler обработчик сообщения, пришедшего по подписке pub/sub
+type MessageHandler func(channel string, data []byte)
+
+// Subscription подписка pub/sub на отдельном соединении, каналы можно добавлять и убирать, пока идет чтение
+type Subscription interface {
+	Subscribe(ctx context.Context, channels ...string) error
+	Unsubscribe(ctx context.Context, channels ...string) error
+	// Receive передает сообщения в handler, пока не отменен контекст или не оборвалось соединение, и закрывает его.
+	// После возврата подписка больше не работает, нужна новая
+	Receive(ctx context.Context, handler MessageHandler) error
+	// Close закрывает соединение подписки, которую не стали читать. Повторное закрытие ничего не делает
+	Close() error
+}
+
 // Client Клиент-обертка к редису
 type Client interface {
 	Set(ctx context.Context, key string, value interface{}) error
 	SetEx(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	// Get возвращает значение ключа или ErrNil, если ключа нет
 	Get(ctx context.Context, key string) (string, error)
 	Del(ctx context.Context, key string) error
 	Expire(ctx context.Context, key string, expiration time.Duration) error
@@ -18,4 +37,9 @@ type Client interface {
 	HGet(ctx context.Context, key string, field string) (string, error)
 	HDel(ctx context.Context, key string, field string) error
 	HGetAll(ctx context.Context, key string, dest interface{}) error
+	Publish(ctx context.Context, channel string, message interface{}) error
+	// NewSubscription подписка, набор каналов которой меняется на ходу
+	NewSubscription() Subscription
+	// Eval атомарно выполняет lua-скрипт. Скрипт кешируется на сервере и передается целиком, только если его там еще нет
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
 }
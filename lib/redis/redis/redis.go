@@ -2,7 +2,9 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/gomodule/redigo/redis"
@@ -36,7 +38,7 @@ func (c *client) Set(ctx context.Context, key string, value interface{}) error {
 
 func (c *client) SetEx(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
 	err := c.exec(ctx, func(ctx context.Context, conn redis.Conn) error {
-		_, err := conn.Do("SETEX", redis.Args{key}.Add(value).Add(int(expiration.Seconds()))...)
+		_, err := conn.Do("SETEX", key, int(expiration.Seconds()), value)
 
 		return err
 	})
@@ -45,18 +47,21 @@ func (c *client) SetEx(ctx context.Context, key string, value interface{}, expir
 }
 
 func (c *client) Get(ctx context.Context, key string) (string, error) {
-	var res interface{}
+	var res string
 	err := c.exec(ctx, func(ctx context.Context, conn redis.Conn) error {
 		var errCmd error
-		res, errCmd = conn.Do("GET", key)
+		res, errCmd = redis.String(conn.Do("GET", key))
 
 		return errCmd
 	})
+	if errors.Is(err, redis.ErrNil) {
+		return "", def.ErrNil
+	}
 	if err != nil {
 		return "", err
 	}
 
-	return res.(string), nil
+	return res, nil
 }
 
 func (c *client) Del(ctx context.Context, key string) error {
@@ -160,6 +165,89 @@ func (c *client) HGetAll(ctx context.Context, key string, dest interface{}) erro
 	return err
 }
 
+func (c *client) Publish(ctx context.Context, channel string, message interface{}) error {
+	err := c.exec(ctx, func(ctx context.Context, conn redis.Conn) error {
+		_, err := conn.Do("PUBLISH", channel, message)
+
+		return err
+	})
+
+	return err
+}
+
+func (c *client) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	var res interface{}
+	err := c.exec(ctx, func(ctx context.Context, conn redis.Conn) error {
+		var errCmd error
+		// Script сначала пробует EVALSHA и только при NOSCRIPT отправляет сам скрипт
+		res, errCmd = redis.NewScript(len(keys), script).DoContext(ctx, conn, redis.Args{}.AddFlat(keys).Add(args...)...)
+
+		return errCmd
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *client) NewSubscription() def.Subscription {
+	return &subscription{psc: redis.PubSubConn{Conn: c.conn.Get()}}
+}
+
+// subscription держит отдельное соединение в режиме pub/sub. Читать из него может один Receive,
+// а команды подписки приходят из других горутин, поэтому их запись идет под мьютексом
+type subscription struct {
+	psc       redis.PubSubConn
+	m         sync.Mutex
+	closeOnce sync.Once
+}
+
+func (s *subscription) Subscribe(_ context.Context, channels ...string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	return s.psc.Subscribe(redis.Args{}.AddFlat(channels)...)
+}
+
+func (s *subscription) Unsubscribe(_ context.Context, channels ...string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	return s.psc.Unsubscribe(redis.Args{}.AddFlat(channels)...)
+}
+
+func (s *subscription) Receive(ctx context.Context, handler def.MessageHandler) error {
+	defer func() {
+		err := s.Close()
+		if err != nil {
+			log.Printf("redis close pubsub conn err: %v", err)
+		}
+	}()
+
+	for {
+		switch v := s.psc.ReceiveContext(ctx).(type) {
+		case redis.Message:
+			handler(v.Channel, v.Data)
+		case error:
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return v
+		}
+	}
+}
+
+func (s *subscription) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		err = s.psc.Close()
+	})
+
+	return err
+}
+
 func (c *client) exec(ctx context.Context, handlerFunc handler) error {
 	conn := c.conn.Get()
 	defer func() {
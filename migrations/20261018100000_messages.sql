@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE chat.messages
+(
+    id         bigserial primary key,
+    chat_id    int references chat.chats(id),
+    user_id    bigint not null,
+    text       text not null,
+    created_at timestamp default CURRENT_TIMESTAMP
+);
+CREATE INDEX messages_chat_id_idx ON chat.messages (chat_id, created_at, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE chat.messages;
+-- +goose StatementEnd
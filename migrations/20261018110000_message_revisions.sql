@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE chat.messages
+    ADD COLUMN updated_at timestamp,
+    ADD COLUMN deleted_at timestamp;
+CREATE TABLE chat.message_revisions
+(
+    id         bigserial primary key,
+    message_id bigint not null references chat.messages(id),
+    action     text not null,
+    text       text not null,
+    changed_by bigint not null,
+    created_at timestamp default CURRENT_TIMESTAMP
+);
+CREATE INDEX message_revisions_message_id_idx ON chat.message_revisions (message_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE chat.message_revisions;
+ALTER TABLE chat.messages
+    DROP COLUMN updated_at,
+    DROP COLUMN deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE chat.outbox
+(
+    id           bigserial primary key,
+    topic        text not null,
+    key          text not null,
+    type         text not null,
+    payload      jsonb not null,
+    created_at   timestamp default CURRENT_TIMESTAMP,
+    published_at timestamp
+);
+CREATE INDEX outbox_unpublished_idx ON chat.outbox (id) WHERE published_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE chat.outbox;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE chat.chat_users
+    ALTER COLUMN user_name SET DEFAULT '',
+    ADD PRIMARY KEY (chat_id, user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE chat.chat_users
+    DROP CONSTRAINT chat_users_pkey,
+    ALTER COLUMN user_name DROP DEFAULT;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE chat.chats
+    ALTER COLUMN id ADD GENERATED BY DEFAULT AS IDENTITY,
+    ADD COLUMN title text not null default '',
+    ADD COLUMN description text not null default '',
+    ADD COLUMN type text not null default 'group',
+    ADD COLUMN created_by bigint;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE chat.chats
+    ALTER COLUMN id DROP IDENTITY,
+    DROP COLUMN title,
+    DROP COLUMN description,
+    DROP COLUMN type,
+    DROP COLUMN created_by;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE chat.chat_users ADD COLUMN role text not null default 'member';
+UPDATE chat.chat_users cu SET role = 'owner'
+FROM chat.chats c
+WHERE c.id = cu.chat_id AND c.created_by = cu.user_id;
+
+ALTER TABLE chat.chats ADD COLUMN pinned_message_id bigint references chat.messages(id) on delete set null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE chat.chats DROP COLUMN pinned_message_id;
+ALTER TABLE chat.chat_users DROP COLUMN role;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE chat.invites
+(
+    id         bigserial primary key,
+    code       text not null unique,
+    chat_id    int not null references chat.chats(id) on delete cascade,
+    created_by bigint not null,
+    role       text not null default 'member',
+    max_uses   int not null default 0,
+    uses       int not null default 0,
+    expires_at timestamp,
+    revoked_at timestamp,
+    created_at timestamp not null default CURRENT_TIMESTAMP
+);
+CREATE INDEX invites_chat_id_idx ON chat.invites (chat_id);
+
+-- invite_uses кто и когда вступил по приглашению
+CREATE TABLE chat.invite_uses
+(
+    invite_id bigint not null references chat.invites(id) on delete cascade,
+    user_id   bigint not null,
+    used_at   timestamp not null default CURRENT_TIMESTAMP
+);
+CREATE INDEX invite_uses_invite_id_idx ON chat.invite_uses (invite_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE chat.invite_uses;
+DROP TABLE chat.invites;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE chat.bans
+(
+    chat_id   int not null references chat.chats(id) on delete cascade,
+    user_id   bigint not null,
+    banned_by bigint not null,
+    reason    text not null default '',
+    banned_at timestamp not null default CURRENT_TIMESTAMP,
+    primary key (chat_id, user_id)
+);
+
+ALTER TABLE chat.chat_users
+    ADD COLUMN muted boolean not null default false,
+    ADD COLUMN muted_until timestamp;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE chat.chat_users
+    DROP COLUMN muted,
+    DROP COLUMN muted_until;
+DROP TABLE chat.bans;
+-- +goose StatementEnd
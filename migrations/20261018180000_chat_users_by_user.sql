@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX chat_users_user_id_idx ON chat.chat_users (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX chat.chat_users_user_id_idx;
+-- +goose StatementEnd
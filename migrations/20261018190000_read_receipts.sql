@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- last_read_message_id до какого сообщения включительно участник прочитал чат
+ALTER TABLE chat.chat_users ADD COLUMN last_read_message_id bigint not null default 0;
+ALTER TABLE chat.chat_users ADD COLUMN last_read_at timestamp;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE chat.chat_users DROP COLUMN last_read_at;
+ALTER TABLE chat.chat_users DROP COLUMN last_read_message_id;
+-- +goose StatementEnd
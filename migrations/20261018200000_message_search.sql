@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- конфигурация поиска хранится в каждом сообщении, чтобы смена языка не ломала уже проиндексированные сообщения
+ALTER TABLE chat.messages ADD COLUMN search_config regconfig not null default 'russian';
+ALTER TABLE chat.messages ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector(search_config, text)) STORED;
+CREATE INDEX messages_search_idx ON chat.messages USING gin (search_vector);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX chat.messages_search_idx;
+ALTER TABLE chat.messages DROP COLUMN search_vector;
+ALTER TABLE chat.messages DROP COLUMN search_config;
+-- +goose StatementEnd
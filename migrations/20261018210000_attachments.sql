@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE chat.attachments
+(
+    id          bigserial primary key,
+    chat_id     int not null references chat.chats(id) on delete cascade,
+    -- пока null, файл загружен, но не прикреплен к сообщению
+    message_id  bigint references chat.messages(id) on delete cascade,
+    uploaded_by bigint not null,
+    blob_key    text not null,
+    file_name   text not null,
+    mime_type   text not null,
+    size        bigint not null,
+    created_at  timestamp not null default CURRENT_TIMESTAMP
+);
+CREATE INDEX attachments_message_id_idx ON chat.attachments (message_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE chat.attachments;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE chat.messages ADD COLUMN mentions jsonb not null default '[]';
+
+-- лента упоминаний: кого упомянули в каком сообщении и прочитал ли он
+CREATE TABLE chat.mentions
+(
+    user_id    bigint not null,
+    message_id bigint not null references chat.messages(id) on delete cascade,
+    chat_id    int not null references chat.chats(id) on delete cascade,
+    created_at timestamp not null,
+    read_at    timestamp,
+    primary key (user_id, message_id)
+);
+CREATE INDEX mentions_feed_idx ON chat.mentions (user_id, created_at, message_id);
+CREATE INDEX mentions_unread_idx ON chat.mentions (user_id, chat_id) WHERE read_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE chat.mentions;
+ALTER TABLE chat.messages DROP COLUMN mentions;
+-- +goose StatementEnd
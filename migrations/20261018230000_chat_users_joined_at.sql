@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- joined_at когда пользователь вступил в чат, по нему выбирается преемник владельца
+ALTER TABLE chat.chat_users ADD COLUMN joined_at timestamp not null default now();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE chat.chat_users DROP COLUMN joined_at;
+-- +goose StatementEnd
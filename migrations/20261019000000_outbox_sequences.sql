@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- outbox_sequences последний номер события по ключу (чату). Транзакция держит строку счетчика заблокированной
+-- до фиксации, поэтому события одного чата фиксируются строго в порядке номеров и без пропусков
+CREATE TABLE chat.outbox_sequences
+(
+    key text primary key,
+    seq bigint not null
+);
+ALTER TABLE chat.outbox ADD COLUMN seq bigint not null default 0;
+CREATE INDEX outbox_published_idx ON chat.outbox (published_at) WHERE published_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX chat.outbox_published_idx;
+ALTER TABLE chat.outbox DROP COLUMN seq;
+DROP TABLE chat.outbox_sequences;
+-- +goose StatementEnd
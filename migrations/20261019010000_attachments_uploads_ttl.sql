@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- неприкрепленные загрузки удаляются по сроку, а их содержимое - если на него больше никто не ссылается
+CREATE INDEX attachments_unattached_idx ON chat.attachments (created_at) WHERE message_id IS NULL;
+CREATE INDEX attachments_blob_key_idx ON chat.attachments (blob_key);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX chat.attachments_blob_key_idx;
+DROP INDEX chat.attachments_unattached_idx;
+-- +goose StatementEnd